@@ -0,0 +1,249 @@
+package chip8
+
+const maxBlockLength = 64
+
+// After this many invalidations of the block at an address, that address is left to the interpreter
+const maxBlockRecompiles = 4
+
+type blockFunc func(cpu *Cpu) error
+
+type block struct {
+	start  uint16
+	end    uint16 // Address after the last instruction
+	length int    // Instructions in the block
+	run    blockFunc
+}
+
+// BlockEngine is an alternative to calling Tick, for bulk headless runs. It compiles straight-line runs
+// of instructions into chained closures, ending each block at a jump, skip, draw or key wait, and runs
+// a whole block per step. Blocks are dropped when memory they cover is written through Set8/Set16, and all
+// of them when LoadROM or LoadState reloads memory; code that keeps modifying itself falls back to the interpreter.
+type BlockEngine struct {
+	cpu *Cpu
+
+	blocks       map[uint16]*block
+	code         []bool // Bytes covered by at least one compiled block
+	recompiles   []uint8
+	extensionGen uint64 // The Cpu's extensionGen when the blocks were compiled
+	dirty        bool   // A compiled block was invalidated during the current step
+	stopped      bool   // The current block stopped before its last instruction
+}
+
+func NewBlockEngine(cpu *Cpu) *BlockEngine {
+	engine := &BlockEngine{
+		cpu:          cpu,
+		blocks:       make(map[uint16]*block),
+		code:         make([]bool, len(cpu.Memory.Memory)),
+		recompiles:   make([]uint8, len(cpu.Memory.Memory)),
+		extensionGen: cpu.extensionGen,
+	}
+
+	cpu.Memory.addWriteObserver(engine)
+
+	return engine
+}
+
+// Detaches the engine from the Cpu's memory. The Cpu can carry on with Tick.
+func (engine *BlockEngine) Close() {
+	engine.cpu.Memory.removeWriteObserver(engine)
+}
+
+// Drops every compiled block - needed after writing cpu.Memory.Memory directly, other than through LoadROM
+// or LoadState
+func (engine *BlockEngine) Flush() {
+	engine.blocks = make(map[uint16]*block)
+	clear(engine.code)
+}
+
+func (engine *BlockEngine) reload() {
+	engine.Flush()
+}
+
+func (engine *BlockEngine) invalidate(addr uint16) {
+	if int(addr) >= len(engine.code) || !engine.code[addr] {
+		return
+	}
+
+	for start, b := range engine.blocks {
+		if addr >= b.start && addr < b.end {
+			delete(engine.blocks, start)
+			if engine.recompiles[start] < maxBlockRecompiles {
+				engine.recompiles[start]++
+			}
+			engine.dirty = true
+		}
+	}
+}
+
+// Runs blocks until at least n instructions have executed. Returns the number executed.
+func (engine *BlockEngine) Run(n int) (int, error) {
+	executed := 0
+
+	for executed < n {
+		count, err := engine.Step()
+		executed += count
+		if err != nil {
+			return executed, err
+		}
+	}
+
+	return executed, nil
+}
+
+// Runs the block at PC, and returns the number of instructions executed. With a Trace, timing model, custom Bus
+// or execute hooks attached, or at addresses that keep being modified, a single instruction is interpreted instead.
+func (engine *BlockEngine) Step() (int, error) {
+	cpu := engine.cpu
+
+	if cpu.NeedsTick() || int(cpu.PC) >= len(engine.code) || engine.recompiles[cpu.PC] >= maxBlockRecompiles {
+		return 1, cpu.Tick()
+	}
+
+	if engine.extensionGen != cpu.extensionGen {
+		engine.Flush()
+		engine.extensionGen = cpu.extensionGen
+	}
+
+	b, ok := engine.blocks[cpu.PC]
+	if !ok {
+		var err error
+		b, err = engine.compile(cpu.PC)
+		if err != nil {
+			return 1, cpu.Tick()
+		}
+	}
+
+	engine.dirty = false
+	engine.stopped = false
+
+	err := b.run(cpu)
+	if engine.stopped {
+		// Every instruction before PC has run
+		return int(cpu.PC-b.start) / 2, err
+	}
+
+	return b.length, err
+}
+
+func (engine *BlockEngine) compile(start uint16) (*block, error) {
+	var insts []Instruction
+	var addrs []uint16
+
+	pc := start
+	for len(insts) < maxBlockLength {
+		// Fetched as the interpreter does, without read hooks. Step never compiles with execute hooks attached.
+		opcode, err := engine.cpu.Memory.Fetch16(pc)
+		if err != nil {
+			if len(insts) == 0 {
+				return nil, err
+			}
+			break
+		}
+
+		inst := engine.cpu.Decode(opcode)
+		insts = append(insts, inst)
+		addrs = append(addrs, pc)
+		pc += 2
+
+		if endsBlock(inst.Op) {
+			break
+		}
+	}
+
+	b := &block{start: start, end: pc, length: len(insts)}
+
+	// The final instruction runs through the interpreter's handlers, with PC already past it
+	var run blockFunc
+	last := insts[len(insts)-1]
+	if endsBlock(last.Op) {
+		next := pc
+		run = func(cpu *Cpu) error {
+			cpu.PC = next
+			return execute(last, cpu)
+		}
+		insts = insts[:len(insts)-1]
+	} else {
+		next := pc
+		run = func(cpu *Cpu) error {
+			cpu.PC = next
+			return nil
+		}
+	}
+
+	for i := len(insts) - 1; i >= 0; i-- {
+		run = engine.compileOp(insts[i], addrs[i]+2, run)
+	}
+
+	b.run = run
+	engine.blocks[start] = b
+	for addr := start; addr < pc && int(addr) < len(engine.code); addr++ {
+		engine.code[addr] = true
+	}
+
+	return b, nil
+}
+
+// Straight-line ops that never touch PC are specialised; anything else goes through its handler
+func (engine *BlockEngine) compileOp(inst Instruction, next uint16, rest blockFunc) blockFunc {
+	x, y, nn := inst.X, inst.Y, inst.NN
+
+	switch inst.Op {
+	case OpLD_v_byte:
+		return func(cpu *Cpu) error {
+			cpu.V[x] = nn
+			return rest(cpu)
+		}
+	case OpADD_v_byte:
+		return func(cpu *Cpu) error {
+			cpu.V[x] += nn
+			return rest(cpu)
+		}
+	case OpLD_v1_v2:
+		return func(cpu *Cpu) error {
+			cpu.V[x] = cpu.V[y]
+			return rest(cpu)
+		}
+	case OpOR_v1_v2:
+		return func(cpu *Cpu) error {
+			cpu.V[x] |= cpu.V[y]
+			return rest(cpu)
+		}
+	case OpAND_v1_v2:
+		return func(cpu *Cpu) error {
+			cpu.V[x] &= cpu.V[y]
+			return rest(cpu)
+		}
+	case OpXOR_v1_v2:
+		return func(cpu *Cpu) error {
+			cpu.V[x] ^= cpu.V[y]
+			return rest(cpu)
+		}
+	}
+
+	handler := opHandlers[inst.Op]
+	if handler == nil {
+		return rest
+	}
+
+	return func(cpu *Cpu) error {
+		cpu.PC = next
+		err := handler(cpu, inst)
+		if err != nil || engine.dirty {
+			// Leave the rest of the block to be recompiled from the next instruction
+			engine.stopped = true
+			return err
+		}
+		return rest(cpu)
+	}
+}
+
+func endsBlock(op Op) bool {
+	switch op {
+	case OpJP, OpCALL, OpRET, OpJP_v0_addr,
+		OpSE_v_byte, OpSNE_v_byte, OpSE_v1_v2, OpSNE_v1_v2, OpSKP, OpSKNP,
+		OpCLS, OpDRW, OpLD_v_k,
+		OpSYS, OpEXIT, OpLD_i_long, OpInvalid, OpExtension:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package chip8
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Random program of every implemented op, with jumps and calls kept inside the program and its stack balanced
+func randomBlockTestProgram(cpu *Cpu) {
+	const size = 0x80
+
+	for addr := uint16(0x200); addr < 0x200+size; addr += 2 {
+		x, y, nn := uint16(rand.Intn(0x10)), uint16(rand.Intn(0x10)), uint16(rand.Intn(0x100))
+		target := 0x200 + uint16(rand.Intn(size/2))*2
+
+		var opcode uint16
+		switch rand.Intn(12) {
+		case 0:
+			opcode = 0x6000 | x<<8 | nn
+		case 1:
+			opcode = 0x7000 | x<<8 | nn
+		case 2, 3:
+			opcode = 0x8000 | x<<8 | y<<4 | uint16(rand.Intn(5))
+		case 4:
+			opcode = 0x3000 | x<<8 | nn
+		case 5:
+			opcode = 0x4000 | x<<8 | nn
+		case 6:
+			opcode = 0x5000 | x<<8 | y<<4
+		case 7:
+			opcode = 0x1000 | target
+		case 8:
+			opcode = 0x2000 | target
+		case 9:
+			opcode = 0x00EE
+		case 10:
+			opcode = 0x00E0
+		default:
+			opcode = 0x6000 | x<<8 | nn
+		}
+
+		cpu.Memory.Set16(addr, opcode)
+	}
+	cpu.Memory.Set16(0x200+size, 0x1200)
+}
+
+func TestBlockEngine_MatchesTick(t *testing.T) {
+	const n_tests = 50
+	const n_steps = 500
+
+	for i := 0; i < n_tests; i++ {
+		interpreted := NewCpu()
+		randomBlockTestProgram(interpreted)
+
+		compiled := NewCpu()
+		compiled.LoadState(interpreted.SaveState())
+		engine := NewBlockEngine(compiled)
+
+		for step := 0; step < n_steps; step++ {
+			count, compiledErr := engine.Step()
+
+			var interpretedErr error
+			for range count {
+				interpretedErr = interpreted.Tick()
+				if interpretedErr != nil {
+					break
+				}
+			}
+
+			if (compiledErr != nil) != (interpretedErr != nil) {
+				t.Fatalf("step %v: interpreter error = %v, block engine error = %v", step, interpretedErr, compiledErr)
+			}
+
+			if !bytes.Equal(interpreted.SaveState(), compiled.SaveState()) {
+				t.Fatalf("step %v: block engine state diverged from interpreter:\n%v\nwant:\n%v",
+					step, compiled.GetPrettyCpuState(), interpreted.GetPrettyCpuState())
+			}
+
+			if compiledErr != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestBlockEngine_SelfModifying(t *testing.T) {
+	cpu := NewCpu()
+	engine := NewBlockEngine(cpu)
+
+	// 0x200: ADD V0, 0x01 / ADD V1, 0x01 / JP 0x200
+	cpu.Memory.Set16(0x200, 0x7001)
+	cpu.Memory.Set16(0x202, 0x7101)
+	cpu.Memory.Set16(0x204, 0x1200)
+
+	for round := 1; round <= 2*maxBlockRecompiles; round++ {
+		count, err := engine.Step()
+		if err != nil {
+			t.Fatalf("BlockEngine.Step() error = %v", err)
+		}
+
+		if round <= maxBlockRecompiles && count != 3 {
+			t.Errorf("round %v: BlockEngine.Step() ran %v instructions, want whole block of 3", round, count)
+		}
+		if round > maxBlockRecompiles {
+			if count != 1 {
+				t.Errorf("round %v: BlockEngine.Step() ran %v instructions, want interpreter fallback", round, count)
+			}
+			engine.Run(2)
+		}
+
+		// Rewrite the increment for V1 every round
+		cpu.Memory.Set16(0x202, 0x7100|uint16(round+1))
+	}
+
+	// V1 received 1, 2, 3 ... in turn
+	want := uint8(0)
+	for round := 1; round <= 2*maxBlockRecompiles; round++ {
+		want += uint8(round)
+	}
+	if cpu.V[0x1] != want {
+		t.Errorf("V1 = %02X, want %02X", cpu.V[0x1], want)
+	}
+}
+
+// Read hooks apply to data reads, not instruction fetch, so must not change the code blocks are compiled from
+func TestBlockEngine_ReadHooks(t *testing.T) {
+	cpu := NewCpu()
+	engine := NewBlockEngine(cpu)
+
+	// 0x200: LD V0, 0x05 / JP 0x200
+	cpu.Memory.Set16(0x200, 0x6005)
+	cpu.Memory.Set16(0x202, 0x1200)
+	cpu.Memory.AddReadHook(0x200, 0x203, func(addr uint16, val uint8) (uint8, error) {
+		return 0x00, nil
+	})
+
+	count, err := engine.Step()
+	if err != nil {
+		t.Fatalf("BlockEngine.Step() error = %v", err)
+	}
+	if count != 2 || cpu.V[0x0] != 0x05 || cpu.PC != 0x200 {
+		t.Errorf("BlockEngine.Step() ran %v instructions to V0 = %02X, PC = %04X, want 2, 05 and 0200", count, cpu.V[0x0], cpu.PC)
+	}
+}
+
+// LoadROM and LoadState write Memory directly, as a rewind or bug report restore does
+func TestBlockEngine_Reload(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x01, 0x12, 0x00}) // LD V0, 0x01 / JP 0x200
+	engine := NewBlockEngine(cpu)
+	defer engine.Close()
+
+	run := func(want uint8) {
+		t.Helper()
+		_, err := engine.Run(2)
+		if err != nil {
+			t.Fatalf("BlockEngine.Run() error = %v", err)
+		}
+		if cpu.V[0x0] != want {
+			t.Errorf("BlockEngine.Run() left V0 = %v, want %v", cpu.V[0x0], want)
+		}
+	}
+
+	run(1)
+	state := cpu.SaveState()
+
+	cpu.LoadROM([]byte{0x60, 0x02, 0x12, 0x00}) // LD V0, 0x02 / JP 0x200
+	run(2)
+
+	err := cpu.LoadState(state)
+	if err != nil {
+		t.Fatalf("Cpu.LoadState() error = %v", err)
+	}
+	run(1)
+}
+
+func TestBlockEngine_Close(t *testing.T) {
+	cpu := NewCpu()
+	engine := NewBlockEngine(cpu)
+	engine.Close()
+
+	if len(cpu.Memory.writeObservers) != 0 {
+		t.Errorf("BlockEngine.Close() left %v write observers registered", len(cpu.Memory.writeObservers))
+	}
+}
+
+func BenchmarkBlockEngine(b *testing.B) {
+	cpu := newBenchmarkCpu()
+	engine := NewBlockEngine(cpu)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	executed, err := engine.Run(b.N)
+	if err != nil {
+		b.Fatalf("BlockEngine.Run() error = %v", err)
+	}
+
+	b.ReportMetric(float64(executed)/b.Elapsed().Seconds(), "instructions/s")
+}
@@ -0,0 +1,264 @@
+package chip8
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	bugReportManifest = "report.json"
+	bugReportROM      = "rom.ch8"
+	bugReportMovie    = "input.c8mv"
+	bugReportTrace    = "trace.txt"
+	bugReportState    = "state.bin"
+)
+
+// Largest file read from a bug report, well past any real ROM, state or movie, so a hostile zip cannot
+// exhaust memory
+const bugReportMaxFile = 16 << 20
+
+// BugReport bundles everything needed to reproduce a failure seen in the field
+type BugReport struct {
+	Error      string
+	Settings   RunSettings  // Platform, scheduler and timing settings of the run
+	Extensions []*Extension // The Cpu's extensions, in the order added. Handlers are not stored, so a read report's have none.
+	ROM        []byte
+	Movie      *Movie
+	Trace      []TraceEntry // Last instructions executed before the failure, oldest first
+	State      []byte       // Saved state at the point of failure
+}
+
+type bugReportManifestFields struct {
+	Error      string
+	Settings   RunSettings
+	Extensions []bugReportExtension
+	Trace      []bugReportTraceEntry
+	Frames     int
+}
+
+// Extension without its handler, which cannot be stored
+type bugReportExtension struct {
+	Name       string
+	Mask       uint16
+	Value      uint16
+	Priority   ExtensionPriority
+	Mnemonic   string
+	Format     string
+	OctoFormat string
+}
+
+type bugReportTraceEntry struct {
+	PC     uint16
+	Opcode uint16
+	Ext    int `json:",omitempty"` // 1 + index into the manifest's Extensions, 0 for a built-in op
+}
+
+// Captures the current state of the scheduler's Cpu after a frame returned tickErr. movie may be nil; when it
+// holds every frame before the failing one, as a Movie subscribed to the scheduler does, the failing frame's
+// keys are added to it so that replaying it reaches the failure.
+func NewBugReport(scheduler *Scheduler, rom []byte, movie *Movie, tickErr error) *BugReport {
+	cpu := scheduler.cpu
+	if movie != nil && tickErr != nil && uint64(len(movie.Keys)) == scheduler.Frame() {
+		movie.RecordPartial(cpu)
+	}
+
+	report := &BugReport{
+		Settings: scheduler.Settings(),
+		ROM:      rom,
+		Movie:    movie,
+		State:    cpu.SaveState(),
+	}
+
+	if cpu.extensions != nil {
+		for _, entry := range cpu.extensions.entries {
+			report.Extensions = append(report.Extensions, entry.ext)
+		}
+	}
+
+	if tickErr != nil {
+		report.Error = tickErr.Error()
+	}
+	if cpu.Trace != nil {
+		report.Trace = cpu.Trace.Entries()
+	}
+
+	return report
+}
+
+func (report *BugReport) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	manifest := bugReportManifestFields{
+		Error:    report.Error,
+		Settings: report.Settings,
+	}
+	if report.Movie != nil {
+		manifest.Frames = len(report.Movie.Keys)
+	}
+
+	indices := make(map[*Extension]int)
+	addExtension := func(ext *Extension) int {
+		if _, ok := indices[ext]; !ok {
+			manifest.Extensions = append(manifest.Extensions, bugReportExtension{
+				Name: ext.Name, Mask: ext.Mask, Value: ext.Value, Priority: ext.Priority,
+				Mnemonic: ext.Mnemonic, Format: ext.Format, OctoFormat: ext.OctoFormat,
+			})
+			indices[ext] = len(manifest.Extensions)
+		}
+		return indices[ext]
+	}
+	for _, ext := range report.Extensions {
+		addExtension(ext)
+	}
+	for _, entry := range report.Trace {
+		traceEntry := bugReportTraceEntry{PC: entry.PC, Opcode: entry.Opcode}
+		if entry.Ext != nil {
+			traceEntry.Ext = addExtension(entry.Ext) // Extensions removed since they ran are only in the trace
+		}
+		manifest.Trace = append(manifest.Trace, traceEntry)
+	}
+
+	manifestJson, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var movie bytes.Buffer
+	if report.Movie != nil {
+		err = report.Movie.Write(&movie)
+		if err != nil {
+			return err
+		}
+	}
+
+	var trace strings.Builder
+	for _, entry := range report.Trace {
+		trace.WriteString(entry.String())
+		trace.WriteRune('\n')
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{bugReportManifest, manifestJson},
+		{bugReportROM, report.ROM},
+		{bugReportMovie, movie.Bytes()},
+		{bugReportTrace, []byte(trace.String())},
+		{bugReportState, report.State},
+	}
+
+	for _, file := range files {
+		if file.name == bugReportMovie && report.Movie == nil {
+			continue
+		}
+
+		fw, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		_, err = fw.Write(file.data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func ReadBugReport(r io.ReaderAt, size int64) (*BugReport, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("opening bug report: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		fr, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %v in bug report: %w", file.Name, err)
+		}
+
+		files[file.Name], err = io.ReadAll(io.LimitReader(fr, bugReportMaxFile+1))
+		fr.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %v in bug report: %w", file.Name, err)
+		}
+		if len(files[file.Name]) > bugReportMaxFile {
+			return nil, fmt.Errorf("%v in bug report is larger than %v bytes", file.Name, bugReportMaxFile)
+		}
+	}
+
+	for _, name := range []string{bugReportManifest, bugReportROM, bugReportState} {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("bug report missing %v", name)
+		}
+	}
+
+	var manifest bugReportManifestFields
+	err = json.Unmarshal(files[bugReportManifest], &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("reading bug report manifest: %w", err)
+	}
+
+	report := &BugReport{
+		Error:    manifest.Error,
+		Settings: manifest.Settings,
+		ROM:      files[bugReportROM],
+		State:    files[bugReportState],
+	}
+
+	extensions := make([]*Extension, len(manifest.Extensions))
+	for i, ext := range manifest.Extensions {
+		extensions[i] = &Extension{
+			Name: ext.Name, Mask: ext.Mask, Value: ext.Value, Priority: ext.Priority,
+			Mnemonic: ext.Mnemonic, Format: ext.Format, OctoFormat: ext.OctoFormat,
+		}
+	}
+	report.Extensions = extensions
+	if len(extensions) == 0 {
+		report.Extensions = nil
+	}
+
+	if movie, ok := files[bugReportMovie]; ok {
+		report.Movie, err = ReadMovie(bytes.NewReader(movie))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range manifest.Trace {
+		traceEntry := TraceEntry{PC: entry.PC, Opcode: entry.Opcode}
+		if entry.Ext < 0 || entry.Ext > len(extensions) {
+			return nil, fmt.Errorf("bug report trace entry at %04X names unknown extension %v", entry.PC, entry.Ext)
+		}
+		if entry.Ext > 0 {
+			traceEntry.Ext = extensions[entry.Ext-1]
+		}
+		report.Trace = append(report.Trace, traceEntry)
+	}
+
+	return report, nil
+}
+
+// Returns a Scheduler, set up as the report's was, with its Cpu restored to the point of failure. It is not run,
+// so it stays paused at the crash until the caller resumes it. Extensions outside the platform's instruction set
+// variant are not restored, as their handlers are not stored - add them to the Cpu before resuming.
+func (report *BugReport) Restore() (*Scheduler, error) {
+	scheduler, err := NewSchedulerWithSettings(report.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("restoring bug report: %w", err)
+	}
+
+	err = scheduler.cpu.LoadState(report.State)
+	if err != nil {
+		return nil, fmt.Errorf("restoring bug report state: %w", err)
+	}
+
+	return scheduler, nil
+}
@@ -0,0 +1,222 @@
+package chip8
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestBugReport_WriteRead(t *testing.T) {
+	// 0x200: LD V0, 0x05 / ADD V1, 0x02 / SE V1, 0x0A / JP 0x202 / RET (underflows)
+	rom := []byte{0x60, 0x05, 0x71, 0x02, 0x31, 0x0A, 0x12, 0x02, 0x00, 0xEE}
+
+	cpu := NewCpu()
+	cpu.Trace = NewTrace(4)
+	cpu.LoadROM(rom)
+	scheduler := NewScheduler(cpu)
+	scheduler.InstructionsPerFrame = 3
+	movie := NewMovie(scheduler.Settings())
+	scheduler.Subscribe(movie)
+
+	var tickErr error
+	for tickErr == nil {
+		tickErr = scheduler.RunFrame()
+	}
+
+	want := NewBugReport(scheduler, rom, movie, tickErr)
+	if len(movie.Keys) != 6 {
+		t.Errorf("NewBugReport() left %v movie frames, want 5 complete and the failing one", len(movie.Keys))
+	}
+
+	var buf bytes.Buffer
+	err := want.Write(&buf)
+	if err != nil {
+		t.Fatalf("BugReport.Write() error = %v", err)
+	}
+
+	got, err := ReadBugReport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadBugReport() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBugReport() = %+v, want %+v", got, want)
+	}
+
+	wantTrace := []TraceEntry{{PC: 0x206, Opcode: 0x1202}, {PC: 0x202, Opcode: 0x7102}, {PC: 0x204, Opcode: 0x310A}, {PC: 0x208, Opcode: 0x00EE}}
+	if !reflect.DeepEqual(got.Trace, wantTrace) {
+		t.Errorf("BugReport.Trace = %v, want %v", got.Trace, wantTrace)
+	}
+
+	restored, err := got.Restore()
+	if err != nil {
+		t.Fatalf("BugReport.Restore() error = %v", err)
+	}
+
+	if restored.Cpu().StateHash() != cpu.StateHash() {
+		t.Errorf("BugReport.Restore() state differs from crash state: %v", restored.Cpu().GetPrettyCpuState())
+	}
+}
+
+func TestBugReport_NoMovie(t *testing.T) {
+	want := NewBugReport(NewScheduler(NewCpu()), []byte{0x00, 0xE0}, nil, nil)
+
+	var buf bytes.Buffer
+	want.Write(&buf)
+
+	got, err := ReadBugReport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadBugReport() error = %v", err)
+	}
+
+	if got.Movie != nil {
+		t.Errorf("ReadBugReport() Movie = %v, want nil", got.Movie)
+	}
+}
+
+func TestBugReport_RestorePlatform(t *testing.T) {
+	platform := PlatformVIP
+	platform.Name = "custom VIP"
+	platform.MemorySize = 0x2000
+	platform.StackTop = 0x1ED0
+
+	cpu, _ := NewCpuWithPlatform(platform)
+	cpu.Timing = DefaultVIPTiming
+	cpu.LoadROM([]byte{0x00, 0xEE})
+	scheduler := NewScheduler(cpu)
+	scheduler.DisplayWait = true
+	tickErr := scheduler.RunFrame()
+
+	var buf bytes.Buffer
+	NewBugReport(scheduler, nil, nil, tickErr).Write(&buf)
+
+	report, err := ReadBugReport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadBugReport() error = %v", err)
+	}
+
+	restored, err := report.Restore()
+	if err != nil {
+		t.Fatalf("BugReport.Restore() error = %v", err)
+	}
+
+	if got := restored.Settings(); !reflect.DeepEqual(got, scheduler.Settings()) {
+		t.Errorf("BugReport.Restore() settings = %+v, want %+v", got, scheduler.Settings())
+	}
+	if restored.Cpu().StateHash() != cpu.StateHash() {
+		t.Errorf("BugReport.Restore() state differs from crash state")
+	}
+}
+
+func TestBugReport_Extensions(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformChip8E)
+	cpu.Trace = NewTrace(2)
+	cpu.AddExtension(Extension{Name: "test", Mask: 0xFFFF, Value: 0x0123, Mnemonic: "NOP", Handler: nopExtensionHandler})
+	cpu.LoadROM([]byte{0x51, 0x21, 0x00, 0xEE}) // SGT V1, V2 / RET (underflows)
+	scheduler := NewScheduler(cpu)
+	tickErr := scheduler.RunFrame()
+
+	var buf bytes.Buffer
+	NewBugReport(scheduler, nil, nil, tickErr).Write(&buf)
+
+	report, err := ReadBugReport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadBugReport() error = %v", err)
+	}
+
+	if got, want := len(report.Extensions), cpu.Extensions().Len(); got != want {
+		t.Fatalf("ReadBugReport() has %v extensions, want %v", got, want)
+	}
+	if last := report.Extensions[len(report.Extensions)-1]; last.Name != "test" || last.Mnemonic != "NOP" || last.Handler != nil {
+		t.Errorf("last extension = %+v, want the test NOP without a handler", last)
+	}
+
+	sgt := report.Trace[0].Ext
+	if sgt == nil || sgt.Mnemonic != "SGT" || !slices.Contains(report.Extensions, sgt) {
+		t.Errorf("BugReport.Trace[0].Ext = %+v, want SGT from the report's extensions", sgt)
+	}
+	if got := report.Trace[0].String(); got != "0200: 5121  SGT V1, V2" {
+		t.Errorf("TraceEntry.String() = %q, want the extension's mnemonic", got)
+	}
+	if report.Trace[1].Ext != nil {
+		t.Errorf("BugReport.Trace[1].Ext = %+v, want nil for RET", report.Trace[1].Ext)
+	}
+}
+
+// The failing frame ends before the Scheduler's subscribers see it, so its keys must still reach the movie
+func TestBugReport_ReplayReachesCrash(t *testing.T) {
+	rom := []byte{0xF0, 0x0A, 0x00, 0xEE} // LD V0, K / RET (underflows)
+
+	cpu := NewCpu()
+	cpu.LoadROM(rom)
+	scheduler := NewScheduler(cpu)
+	movie := NewMovie(scheduler.Settings())
+	scheduler.Subscribe(movie)
+
+	err := scheduler.RunFrames(3)
+	if err != nil {
+		t.Fatalf("Scheduler.RunFrames() error = %v", err)
+	}
+	cpu.Keypad.Press(0x5)
+	tickErr := scheduler.RunFrame()
+	if tickErr == nil {
+		t.Fatalf("Scheduler.RunFrame() did not fail in frame 4")
+	}
+
+	var buf bytes.Buffer
+	NewBugReport(scheduler, rom, movie, tickErr).Write(&buf)
+	report, err := ReadBugReport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadBugReport() error = %v", err)
+	}
+
+	replay, err := NewSchedulerWithSettings(report.Movie.Settings)
+	if err != nil {
+		t.Fatalf("NewSchedulerWithSettings() error = %v", err)
+	}
+	replay.cpu.LoadROM(report.ROM)
+	replay.Subscribe(NewMoviePlayer(report.Movie))
+
+	err = replay.RunFrames(len(report.Movie.Keys))
+	if matched := err != nil && err.Error() == report.Error; !matched || replay.Frame() != 3 {
+		t.Errorf("replay stopped after %v frames, with the reported error %v, want 3 and true", replay.Frame(), matched)
+	}
+}
+
+func newTestZip(files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, data := range files {
+		fw, _ := archive.Create(name)
+		fw.Write(data)
+	}
+	archive.Close()
+	return buf.Bytes()
+}
+
+func TestReadBugReport_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"not a zip": []byte("not a zip file"),
+		"oversized file": newTestZip(map[string][]byte{
+			bugReportManifest: []byte("{}"),
+			bugReportROM:      make([]byte, bugReportMaxFile+1),
+			bugReportState:    {},
+		}),
+		"unknown trace extension": newTestZip(map[string][]byte{
+			bugReportManifest: []byte(`{"Trace": [{"PC": 512, "Opcode": 20769, "Ext": 1}]}`),
+			bugReportROM:      {},
+			bugReportState:    {},
+		}),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadBugReport(bytes.NewReader(data), int64(len(data)))
+			if err == nil {
+				t.Errorf("ReadBugReport() did not throw error as wanted")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package chip8
+
+// Bus is everything the Cpu reads, writes and executes through. Memory is the default implementation.
+type Bus interface {
+	Get8(addr uint16) (uint8, error)
+	Get16(addr uint16) (uint16, error)
+	Set8(addr uint16, val uint8) error
+	Set16(addr uint16, val uint16) error
+	Fetch16(addr uint16) (uint16, error) // Instruction fetch - unlike Get16, runs execute hooks rather than read hooks
+}
+
+// Called with the byte read from addr, returns the value to hand to the reader
+type ReadHook func(addr uint16, val uint8) (uint8, error)
+
+// Called with the byte about to be written to addr, returns the value to store
+type WriteHook func(addr uint16, val uint8) (uint8, error)
+
+// Called before the instruction at addr is fetched
+type ExecuteHook func(addr uint16) error
+
+type HookID int
+
+// Hooks apply to the inclusive address range start - end
+type memoryHooks struct {
+	read    []rangeHook[ReadHook]
+	write   []rangeHook[WriteHook]
+	execute []rangeHook[ExecuteHook]
+}
+
+type rangeHook[T any] struct {
+	id    HookID
+	start uint16
+	end   uint16
+	hook  T
+}
+
+func (mem *Memory) newHook() (*memoryHooks, HookID) {
+	if mem.hooks == nil {
+		mem.hooks = new(memoryHooks)
+	}
+	mem.nextHookID++
+	return mem.hooks, mem.nextHookID
+}
+
+func (mem *Memory) AddReadHook(start uint16, end uint16, hook ReadHook) HookID {
+	hooks, id := mem.newHook()
+	hooks.read = append(hooks.read, rangeHook[ReadHook]{id, start, end, hook})
+	return id
+}
+
+func (mem *Memory) AddWriteHook(start uint16, end uint16, hook WriteHook) HookID {
+	hooks, id := mem.newHook()
+	hooks.write = append(hooks.write, rangeHook[WriteHook]{id, start, end, hook})
+	return id
+}
+
+func (mem *Memory) AddExecuteHook(start uint16, end uint16, hook ExecuteHook) HookID {
+	hooks, id := mem.newHook()
+	hooks.execute = append(hooks.execute, rangeHook[ExecuteHook]{id, start, end, hook})
+	return id
+}
+
+func (mem *Memory) RemoveHook(id HookID) {
+	if mem.hooks == nil {
+		return
+	}
+
+	mem.hooks.read = removeHook(mem.hooks.read, id)
+	mem.hooks.write = removeHook(mem.hooks.write, id)
+	mem.hooks.execute = removeHook(mem.hooks.execute, id)
+
+	if len(mem.hooks.read)+len(mem.hooks.write)+len(mem.hooks.execute) == 0 {
+		mem.hooks = nil
+	}
+}
+
+func removeHook[T any](hooks []rangeHook[T], id HookID) []rangeHook[T] {
+	for i, h := range hooks {
+		if h.id == id {
+			return append(hooks[:i], hooks[i+1:]...)
+		}
+	}
+	return hooks
+}
+
+func (mem *Memory) hasExecuteHooks() bool {
+	return mem.hooks != nil && len(mem.hooks.execute) > 0
+}
+
+func (mem *Memory) runReadHooks(addr uint16, val uint8) (uint8, error) {
+	var err error
+	for _, h := range mem.hooks.read {
+		if addr >= h.start && addr <= h.end {
+			val, err = h.hook(addr, val)
+			if err != nil {
+				return 0x0, err
+			}
+		}
+	}
+	return val, nil
+}
+
+func (mem *Memory) runWriteHooks(addr uint16, val uint8) (uint8, error) {
+	var err error
+	for _, h := range mem.hooks.write {
+		if addr >= h.start && addr <= h.end {
+			val, err = h.hook(addr, val)
+			if err != nil {
+				return 0x0, err
+			}
+		}
+	}
+	return val, nil
+}
+
+func (mem *Memory) runExecuteHooks(addr uint16) error {
+	for _, h := range mem.hooks.execute {
+		if addr >= h.start && addr <= h.end {
+			err := h.hook(addr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
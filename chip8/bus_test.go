@@ -0,0 +1,145 @@
+package chip8
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemory_ReadHook(t *testing.T) {
+	mem := NewMemory()
+	mem.Set16(0x300, 0x1234)
+	mem.Set8(0x400, 0x56)
+
+	var reads []uint16
+	mem.AddReadHook(0x300, 0x301, func(addr uint16, val uint8) (uint8, error) {
+		reads = append(reads, addr)
+		return val + 1, nil
+	})
+
+	got16, _ := mem.Get16(0x300)
+	if got16 != 0x1335 {
+		t.Errorf("Memory.Get16() = %04X through read hook, want 1335", got16)
+	}
+
+	got8, _ := mem.Get8(0x400)
+	if got8 != 0x56 {
+		t.Errorf("Memory.Get8() = %02X outside hook range, want 56", got8)
+	}
+
+	if !reflect.DeepEqual(reads, []uint16{0x300, 0x301}) {
+		t.Errorf("read hook saw %v, want [768 769]", reads)
+	}
+}
+
+func TestMemory_WriteHook(t *testing.T) {
+	mem := NewMemory()
+
+	var port uint8
+	mem.AddWriteHook(0xF00, 0xF00, func(addr uint16, val uint8) (uint8, error) {
+		port = val
+		return 0x00, nil
+	})
+
+	mem.Set8(0xF00, 0x42)
+	if port != 0x42 || mem.Memory[0xF00] != 0x00 {
+		t.Errorf("write hook saw %02X and stored %02X, want 42 and 00", port, mem.Memory[0xF00])
+	}
+
+	mem.Set16(0xEFF, 0xAABB)
+	if port != 0xBB || mem.Memory[0xEFF] != 0xAA {
+		t.Errorf("Memory.Set16() across hook boundary: port %02X, memory %02X, want BB and AA", port, mem.Memory[0xEFF])
+	}
+}
+
+func TestMemory_HookError(t *testing.T) {
+	mem := NewMemory()
+	hookErr := errors.New("denied")
+
+	mem.AddWriteHook(0x000, 0x1FF, func(addr uint16, val uint8) (uint8, error) {
+		return val, hookErr
+	})
+
+	err := mem.Set8(0x010, 0xFF)
+	if !errors.Is(err, hookErr) {
+		t.Errorf("Memory.Set8() error = %v, want hook error", err)
+	}
+	if mem.Memory[0x010] == 0xFF {
+		t.Errorf("Memory.Set8() stored value despite hook error")
+	}
+}
+
+func TestMemory_RemoveHook(t *testing.T) {
+	mem := NewMemory()
+
+	calls := 0
+	id := mem.AddReadHook(0x000, 0xFFF, func(addr uint16, val uint8) (uint8, error) {
+		calls++
+		return val, nil
+	})
+	other := mem.AddExecuteHook(0x000, 0xFFF, func(addr uint16) error { return nil })
+
+	mem.Get8(0x200)
+	mem.RemoveHook(id)
+	mem.Get8(0x200)
+
+	if calls != 1 {
+		t.Errorf("read hook called %v times, want 1 before removal", calls)
+	}
+
+	mem.RemoveHook(other)
+	if mem.hooks != nil {
+		t.Errorf("Memory.RemoveHook() left empty hook registry")
+	}
+}
+
+func TestExecuteHook(t *testing.T) {
+	for _, cached := range []bool{false, true} {
+		cpu := NewCpu()
+		if cached {
+			cpu.EnableDecodeCache()
+		}
+
+		// 0x200: ADD V0, 0x01 / JP 0x200
+		cpu.Memory.Set16(0x200, 0x7001)
+		cpu.Memory.Set16(0x202, 0x1200)
+
+		coverage := make(map[uint16]int)
+		cpu.Memory.AddExecuteHook(0x200, 0xFFF, func(addr uint16) error {
+			coverage[addr]++
+			return nil
+		})
+
+		for range 6 {
+			cpu.Tick()
+		}
+
+		if !reflect.DeepEqual(coverage, map[uint16]int{0x200: 3, 0x202: 3}) {
+			t.Errorf("execute hook coverage = %v with cache %v, want 3 hits each on 200 and 202", coverage, cached)
+		}
+	}
+}
+
+// Bus that serves a fixed instruction from every address
+type constantBus struct {
+	*Memory
+	opcode uint16
+}
+
+func (bus constantBus) Fetch16(addr uint16) (uint16, error) {
+	return bus.opcode, nil
+}
+
+func TestCustomBus(t *testing.T) {
+	cpu := NewCpu()
+	cpu.EnableDecodeCache()
+	cpu.Bus = constantBus{Memory: cpu.Memory, opcode: 0x7102}
+
+	for range 3 {
+		cpu.Tick()
+	}
+
+	if cpu.V[0x1] != 0x06 {
+		t.Errorf("V1 = %02X after 3 ticks through custom bus, want 06", cpu.V[0x1])
+	}
+}
@@ -0,0 +1,346 @@
+package cdp1802
+
+import "fmt"
+
+// Memory as seen by the 1802 - chip8.Memory and chip8.Bus both satisfy this
+type Bus interface {
+	Get8(addr uint16) (uint8, error)
+	Set8(addr uint16, val uint8) error
+}
+
+type Cpu struct {
+	R  [0x10]uint16 // Scratchpad registers R0 - RF
+	D  uint8        // Accumulator
+	DF uint8        // Carry/borrow flag - 0 or 1
+	P  uint8        // Selects the register used as the program counter
+	X  uint8        // Selects the register used as the data pointer
+	T  uint8        // Holds X and P saved by MARK and interrupts
+	IE bool         // Interrupt enable
+	Q  bool         // Output flip-flop
+
+	EF [4]bool // External flag inputs EF1 - EF4, tested by B1 - B4
+
+	Bus    Bus
+	Input  func(port uint8) uint8      // Optional - INP reads 0 from every port when nil
+	Output func(port uint8, val uint8) // Optional - OUT writes are dropped when nil
+
+	Cycles uint64 // Machine cycles executed - 2 per instruction, 3 for long branches and skips
+}
+
+func NewCpu(bus Bus) *Cpu {
+	return &Cpu{Bus: bus, IE: true}
+}
+
+type IdleError struct {
+	Addr uint16
+}
+
+func (err *IdleError) Error() string {
+	return fmt.Sprintf("IDL at %04X - waiting for DMA or interrupt", err.Addr)
+}
+
+func (cpu *Cpu) fetch() (uint8, error) {
+	val, err := cpu.Bus.Get8(cpu.R[cpu.P])
+	cpu.R[cpu.P]++
+	return val, err
+}
+
+// Memory at R(X)
+func (cpu *Cpu) mx() (uint8, error) {
+	return cpu.Bus.Get8(cpu.R[cpu.X])
+}
+
+// Short branches stay in the page of their operand byte, even when it is the last byte of a page
+func (cpu *Cpu) branch(taken bool) error {
+	page := cpu.R[cpu.P] & 0xFF00
+	target, err := cpu.fetch()
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		cpu.R[cpu.P] = page | uint16(target)
+	}
+	return nil
+}
+
+func (cpu *Cpu) longBranch(taken bool) error {
+	cpu.Cycles++
+
+	hi, err := cpu.fetch()
+	if err != nil {
+		return err
+	}
+	lo, err := cpu.fetch()
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		cpu.R[cpu.P] = uint16(hi)<<8 | uint16(lo)
+	}
+	return nil
+}
+
+func (cpu *Cpu) longSkip(taken bool) {
+	cpu.Cycles++
+
+	if taken {
+		cpu.R[cpu.P] += 2
+	}
+}
+
+// D = a + b + carry, setting DF on carry out
+func (cpu *Cpu) add(a uint8, b uint8, carry uint8) {
+	sum := uint16(a) + uint16(b) + uint16(carry)
+	cpu.D = uint8(sum)
+	cpu.DF = uint8(sum >> 8)
+}
+
+// D = a - b - borrow, setting DF when there is no borrow out
+func (cpu *Cpu) sub(a uint8, b uint8, borrow uint8) {
+	cpu.add(a, ^b, 1-borrow)
+}
+
+func (cpu *Cpu) Step() error {
+	addr := cpu.R[cpu.P]
+
+	opcode, err := cpu.fetch()
+	if err != nil {
+		return err
+	}
+	cpu.Cycles += 2
+
+	i, n := opcode>>4, opcode&0xF
+
+	switch i {
+	case 0x0:
+		if n == 0 {
+			cpu.R[cpu.P] = addr
+			return &IdleError{Addr: addr}
+		}
+		cpu.D, err = cpu.Bus.Get8(cpu.R[n]) // LDN
+	case 0x1:
+		cpu.R[n]++ // INC
+	case 0x2:
+		cpu.R[n]-- // DEC
+	case 0x3:
+		err = cpu.branch(cpu.shortCondition(n))
+	case 0x4:
+		cpu.D, err = cpu.Bus.Get8(cpu.R[n]) // LDA
+		cpu.R[n]++
+	case 0x5:
+		err = cpu.Bus.Set8(cpu.R[n], cpu.D) // STR
+	case 0x6:
+		err = cpu.io(n)
+	case 0x7:
+		err = cpu.control(n)
+	case 0x8:
+		cpu.D = uint8(cpu.R[n]) // GLO
+	case 0x9:
+		cpu.D = uint8(cpu.R[n] >> 8) // GHI
+	case 0xA:
+		cpu.R[n] = cpu.R[n]&0xFF00 | uint16(cpu.D) // PLO
+	case 0xB:
+		cpu.R[n] = cpu.R[n]&0x00FF | uint16(cpu.D)<<8 // PHI
+	case 0xC:
+		err = cpu.long(n)
+	case 0xD:
+		cpu.P = n // SEP
+	case 0xE:
+		cpu.X = n // SEX
+	case 0xF:
+		err = cpu.alu(n)
+	}
+
+	return err
+}
+
+// Condition for short branch 3N - the upper half of the range inverts the lower
+func (cpu *Cpu) shortCondition(n uint8) bool {
+	var cond bool
+	switch n & 0x7 {
+	case 0x0:
+		cond = true // BR
+	case 0x1:
+		cond = cpu.Q // BQ
+	case 0x2:
+		cond = cpu.D == 0 // BZ
+	case 0x3:
+		cond = cpu.DF == 1 // BDF
+	default:
+		cond = cpu.EF[n&0x7-4] // B1 - B4
+	}
+
+	if n&0x8 != 0 {
+		return !cond
+	}
+	return cond
+}
+
+func (cpu *Cpu) io(n uint8) error {
+	switch {
+	case n == 0x0: // IRX
+		cpu.R[cpu.X]++
+	case n < 0x8: // OUT
+		val, err := cpu.mx()
+		if err != nil {
+			return err
+		}
+		if cpu.Output != nil {
+			cpu.Output(n, val)
+		}
+		cpu.R[cpu.X]++
+	case n > 0x8: // INP
+		var val uint8
+		if cpu.Input != nil {
+			val = cpu.Input(n - 0x8)
+		}
+		cpu.D = val
+		return cpu.Bus.Set8(cpu.R[cpu.X], val)
+	}
+	return nil
+}
+
+func (cpu *Cpu) control(n uint8) error {
+	switch n {
+	case 0x0, 0x1: // RET, DIS
+		xp, err := cpu.mx()
+		if err != nil {
+			return err
+		}
+		cpu.R[cpu.X]++
+		cpu.X, cpu.P = xp>>4, xp&0xF
+		cpu.IE = n == 0x0
+		return nil
+	case 0x2: // LDXA
+		val, err := cpu.mx()
+		cpu.D = val
+		cpu.R[cpu.X]++
+		return err
+	case 0x3: // STXD
+		err := cpu.Bus.Set8(cpu.R[cpu.X], cpu.D)
+		cpu.R[cpu.X]--
+		return err
+	case 0x6: // SHRC
+		carry := cpu.D & 0x01
+		cpu.D = cpu.D>>1 | cpu.DF<<7
+		cpu.DF = carry
+		return nil
+	case 0x8: // SAV
+		return cpu.Bus.Set8(cpu.R[cpu.X], cpu.T)
+	case 0x9: // MARK
+		cpu.T = cpu.X<<4 | cpu.P
+		err := cpu.Bus.Set8(cpu.R[2], cpu.T)
+		cpu.X = cpu.P
+		cpu.R[2]--
+		return err
+	case 0xA: // REQ
+		cpu.Q = false
+		return nil
+	case 0xB: // SEQ
+		cpu.Q = true
+		return nil
+	case 0xE: // SHLC
+		carry := cpu.D >> 7
+		cpu.D = cpu.D<<1 | cpu.DF
+		cpu.DF = carry
+		return nil
+	}
+
+	// ADC, SDB, SMB and their immediate forms
+	var val uint8
+	var err error
+	if n >= 0xC {
+		val, err = cpu.fetch()
+	} else {
+		val, err = cpu.mx()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch n & 0x3 {
+	case 0x0: // ADC
+		cpu.add(val, cpu.D, cpu.DF)
+	case 0x1: // SDB
+		cpu.sub(val, cpu.D, 1-cpu.DF)
+	case 0x3: // SMB
+		cpu.sub(cpu.D, val, 1-cpu.DF)
+	}
+	return nil
+}
+
+func (cpu *Cpu) long(n uint8) error {
+	switch n {
+	case 0x4: // NOP
+		cpu.Cycles++
+		return nil
+	case 0x5: // LSNQ
+		cpu.longSkip(!cpu.Q)
+	case 0x6: // LSNZ
+		cpu.longSkip(cpu.D != 0)
+	case 0x7: // LSNF
+		cpu.longSkip(cpu.DF == 0)
+	case 0x8: // LSKP
+		cpu.longSkip(true)
+	case 0xC: // LSIE
+		cpu.longSkip(cpu.IE)
+	case 0xD: // LSQ
+		cpu.longSkip(cpu.Q)
+	case 0xE: // LSZ
+		cpu.longSkip(cpu.D == 0)
+	case 0xF: // LSDF
+		cpu.longSkip(cpu.DF == 1)
+	default: // LBR, LBQ, LBZ, LBDF and their inverses
+		cond := cpu.shortCondition(n & 0x3)
+		if n&0x8 != 0 {
+			cond = !cond
+		}
+		return cpu.longBranch(cond)
+	}
+	return nil
+}
+
+func (cpu *Cpu) alu(n uint8) error {
+	if n == 0x6 { // SHR
+		cpu.DF = cpu.D & 0x01
+		cpu.D >>= 1
+		return nil
+	}
+	if n == 0xE { // SHL
+		cpu.DF = cpu.D >> 7
+		cpu.D <<= 1
+		return nil
+	}
+
+	// Memory operand at R(X), or immediate from the upper half of the range
+	var val uint8
+	var err error
+	if n >= 0x8 {
+		val, err = cpu.fetch()
+	} else {
+		val, err = cpu.mx()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch n & 0x7 {
+	case 0x0: // LDX, LDI
+		cpu.D = val
+	case 0x1: // OR, ORI
+		cpu.D |= val
+	case 0x2: // AND, ANI
+		cpu.D &= val
+	case 0x3: // XOR, XRI
+		cpu.D ^= val
+	case 0x4: // ADD, ADI
+		cpu.add(val, cpu.D, 0)
+	case 0x5: // SD, SDI
+		cpu.sub(val, cpu.D, 0)
+	case 0x7: // SM, SMI
+		cpu.sub(cpu.D, val, 0)
+	}
+	return nil
+}
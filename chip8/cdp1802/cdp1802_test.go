@@ -0,0 +1,325 @@
+package cdp1802
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testBus []uint8
+
+func (bus testBus) Get8(addr uint16) (uint8, error) {
+	if int(addr) >= len(bus) {
+		return 0, fmt.Errorf("address out of range: %04X", addr)
+	}
+	return bus[addr], nil
+}
+
+func (bus testBus) Set8(addr uint16, val uint8) error {
+	if int(addr) >= len(bus) {
+		return fmt.Errorf("address out of range: %04X", addr)
+	}
+	bus[addr] = val
+	return nil
+}
+
+const testOrigin = 0x100
+
+func TestStep(t *testing.T) {
+	tests := map[string]struct {
+		program []byte
+		setup   func(cpu *Cpu, bus testBus)
+		want    func(cpu *Cpu, bus testBus) // Applied to a copy of the state before the step
+		cycles  uint64                      // 2 if zero
+	}{
+		"LDN": {
+			program: []byte{0x05},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x200; bus[0x200] = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x42 },
+		},
+		"INC wraps": {
+			program: []byte{0x17},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[7] = 0xFFFF },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[7] = 0x0000 },
+		},
+		"DEC": {
+			program: []byte{0x27},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[7] = 0x1000 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[7] = 0x0FFF },
+		},
+		"BR": {
+			program: []byte{0x30, 0x80},
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x180 },
+		},
+		"BR operand at end of page": {
+			setup: func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x1FE; bus[0x1FE] = 0x30; bus[0x1FF] = 0x80 },
+			want:  func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x180 },
+		},
+		"BZ not taken": {
+			program: []byte{0x32, 0x80},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x01 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = testOrigin + 2 },
+		},
+		"BNZ taken": {
+			program: []byte{0x3A, 0x80},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x01 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x180 },
+		},
+		"B3 on EF3": {
+			program: []byte{0x36, 0x80},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.EF[2] = true },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x180 },
+		},
+		"SKP": {
+			program: []byte{0x38, 0x80},
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = testOrigin + 2 },
+		},
+		"LDA": {
+			program: []byte{0x45},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x200; bus[0x200] = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x42; cpu.R[5] = 0x201 },
+		},
+		"STR": {
+			program: []byte{0x55},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x200; cpu.D = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { bus[0x200] = 0x42 },
+		},
+		"IRX": {
+			program: []byte{0x60},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[2] = 0x201 },
+		},
+		"INP without Input": {
+			program: []byte{0x69},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0xFF; bus[0x200] = 0xFF },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x00; bus[0x200] = 0x00 },
+		},
+		"RET": {
+			program: []byte{0x70},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.IE = false; bus[0x200] = 0x23 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.P = 3; cpu.R[2] = 0x201; cpu.IE = true },
+		},
+		"LDXA": {
+			program: []byte{0x72},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; bus[0x200] = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x42; cpu.R[2] = 0x201 },
+		},
+		"STXD": {
+			program: []byte{0x73},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { bus[0x200] = 0x42; cpu.R[2] = 0x1FF },
+		},
+		"ADC with carry in": {
+			program: []byte{0x74},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0xFF; cpu.DF = 1; bus[0x200] = 0x01 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x01; cpu.DF = 1 },
+		},
+		"SDB with borrow in": {
+			program: []byte{0x75},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x10; cpu.DF = 0; bus[0x200] = 0x20 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x0F; cpu.DF = 1 },
+		},
+		"SHRC": {
+			program: []byte{0x76},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x03; cpu.DF = 1 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x81; cpu.DF = 1 },
+		},
+		"SMB borrowing": {
+			program: []byte{0x77},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x10; cpu.DF = 1; bus[0x200] = 0x20 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0xF0; cpu.DF = 0 },
+		},
+		"MARK": {
+			program: []byte{0x79},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 5; cpu.R[2] = 0x200 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.T = 0x50; bus[0x200] = 0x50; cpu.X = 0; cpu.R[2] = 0x1FF },
+		},
+		"SEQ": {
+			program: []byte{0x7B},
+			want:    func(cpu *Cpu, bus testBus) { cpu.Q = true },
+		},
+		"ADCI": {
+			program: []byte{0x7C, 0x10},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x20; cpu.DF = 1 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x31; cpu.DF = 0; cpu.R[0] = testOrigin + 2 },
+		},
+		"SHLC": {
+			program: []byte{0x7E},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x81; cpu.DF = 0 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x02; cpu.DF = 1 },
+		},
+		"GLO": {
+			program: []byte{0x85},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x1234 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x34 },
+		},
+		"GHI": {
+			program: []byte{0x95},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x1234 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x12 },
+		},
+		"PLO": {
+			program: []byte{0xA5},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x1234; cpu.D = 0xFF },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x12FF },
+		},
+		"PHI": {
+			program: []byte{0xB5},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.R[5] = 0x1234; cpu.D = 0xFF },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[5] = 0xFF34 },
+		},
+		"LBR": {
+			program: []byte{0xC0, 0x12, 0x34},
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = 0x1234 },
+			cycles:  3,
+		},
+		"LBNF not taken": {
+			program: []byte{0xCB, 0x12, 0x34},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.DF = 1 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = testOrigin + 3 },
+			cycles:  3,
+		},
+		"NOP": {
+			program: []byte{0xC4},
+			cycles:  3,
+		},
+		"LSKP": {
+			program: []byte{0xC8},
+			want:    func(cpu *Cpu, bus testBus) { cpu.R[0] = testOrigin + 3 },
+			cycles:  3,
+		},
+		"LSZ not taken": {
+			program: []byte{0xCE},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x01 },
+			cycles:  3,
+		},
+		"SEP": {
+			program: []byte{0xD4},
+			want:    func(cpu *Cpu, bus testBus) { cpu.P = 4 },
+		},
+		"SEX": {
+			program: []byte{0xE2},
+			want:    func(cpu *Cpu, bus testBus) { cpu.X = 2 },
+		},
+		"LDX": {
+			program: []byte{0xF0},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; bus[0x200] = 0x42 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x42 },
+		},
+		"OR": {
+			program: []byte{0xF1},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x0F; bus[0x200] = 0xF0 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0xFF },
+		},
+		"ADD with carry out": {
+			program: []byte{0xF4},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x80; bus[0x200] = 0x81 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x01; cpu.DF = 1 },
+		},
+		"SD": {
+			program: []byte{0xF5},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x10; bus[0x200] = 0x30 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x20; cpu.DF = 1 },
+		},
+		"SHR": {
+			program: []byte{0xF6},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x03 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x01; cpu.DF = 1 },
+		},
+		"SM borrowing": {
+			program: []byte{0xF7},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.X = 2; cpu.R[2] = 0x200; cpu.D = 0x10; bus[0x200] = 0x30 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0xE0; cpu.DF = 0 },
+		},
+		"LDI": {
+			program: []byte{0xF8, 0x42},
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x42; cpu.R[0] = testOrigin + 2 },
+		},
+		"XRI": {
+			program: []byte{0xFB, 0xFF},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x0F },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0xF0; cpu.R[0] = testOrigin + 2 },
+		},
+		"SHL": {
+			program: []byte{0xFE},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x81 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x02; cpu.DF = 1 },
+		},
+		"SMI": {
+			program: []byte{0xFF, 0x01},
+			setup:   func(cpu *Cpu, bus testBus) { cpu.D = 0x10 },
+			want:    func(cpu *Cpu, bus testBus) { cpu.D = 0x0F; cpu.DF = 1; cpu.R[0] = testOrigin + 2 },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bus := make(testBus, 0x400)
+			copy(bus[testOrigin:], test.program)
+
+			cpu := NewCpu(bus)
+			cpu.R[0] = testOrigin
+			if test.setup != nil {
+				test.setup(cpu, bus)
+			}
+
+			want := *cpu
+			wantBus := make(testBus, len(bus))
+			copy(wantBus, bus)
+			want.Bus = wantBus
+			want.R[0] = testOrigin + 1
+			want.Cycles = 2
+			if test.cycles != 0 {
+				want.Cycles = test.cycles
+			}
+			if test.want != nil {
+				test.want(&want, wantBus)
+			}
+
+			err := cpu.Step()
+			if err != nil {
+				t.Fatalf("Cpu.Step() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(*cpu, want) {
+				t.Errorf("Cpu.Step() = %+v, want %+v", *cpu, want)
+			}
+		})
+	}
+}
+
+func TestStep_IO(t *testing.T) {
+	bus := make(testBus, 0x400)
+	copy(bus[testOrigin:], []byte{0x63, 0x6B})
+	bus[0x200] = 0x42
+
+	var port, out uint8
+	cpu := NewCpu(bus)
+	cpu.R[0], cpu.X, cpu.R[2] = testOrigin, 2, 0x200
+	cpu.Output = func(p uint8, val uint8) { port, out = p, val }
+	cpu.Input = func(p uint8) uint8 { return 0x10 | p }
+
+	cpu.Step()
+	if port != 3 || out != 0x42 || cpu.R[2] != 0x201 {
+		t.Errorf("OUT 3 wrote %02X to port %v with R2 = %04X, want 42 to 3 with R2 = 0201", out, port, cpu.R[2])
+	}
+
+	cpu.Step()
+	if cpu.D != 0x13 || bus[0x201] != 0x13 {
+		t.Errorf("INP 3 read D = %02X, M(R2) = %02X, want 13", cpu.D, bus[0x201])
+	}
+}
+
+func TestStep_Idle(t *testing.T) {
+	bus := make(testBus, 0x400)
+	cpu := NewCpu(bus)
+	cpu.R[0] = testOrigin
+
+	err := cpu.Step()
+
+	var idle *IdleError
+	if !errors.As(err, &idle) || idle.Addr != testOrigin || cpu.R[0] != testOrigin {
+		t.Errorf("Cpu.Step() on IDL error = %v, R0 = %04X, want IdleError staying at %04X", err, cpu.R[0], testOrigin)
+	}
+}
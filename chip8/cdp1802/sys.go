@@ -0,0 +1,86 @@
+package cdp1802
+
+import (
+	"fmt"
+
+	"github.com/frasmataz/go-chip8/chip8"
+)
+
+// How a 0nnn machine-code call is set up, following the COSMAC VIP interpreter's register conventions:
+// R3 is the routine's program counter, R2 the stack pointer with X = 2, R5 the CHIP-8 PC and RA the I register.
+// The routine returns to the interpreter with SEP R4.
+type SysOptions struct {
+	StackPointer    uint16 // Initial R2 - with a memory-backed CHIP-8 stack, R2 is that stack's pointer instead
+	MirrorV         bool   // Copy V0 - VF to memory at VRegisters for the routine, and back afterwards
+	VRegisters      uint16
+	MaxInstructions int // Routines still running after this many instructions fail - unlimited if zero
+}
+
+var VIPSysOptions = SysOptions{
+	StackPointer:    0xECF,
+	MirrorV:         true,
+	VRegisters:      0xEF0,
+	MaxInstructions: 1_000_000,
+}
+
+// Returns a handler for chip8.Cpu.Sys that runs 0nnn routines on an 1802 against the Cpu's Bus
+func NewSysHandler(opts SysOptions) chip8.SysHandler {
+	return func(c8 *chip8.Cpu, addr uint16) error {
+		cpu := NewCpu(c8.Bus)
+		cpu.P, cpu.X = 3, 2
+		cpu.R[2] = opts.StackPointer
+		if c8.Platform.MemoryStack {
+			// As on the VIP, R2 points at the free byte below the CHIP-8 return stack, so pushes cannot clobber it
+			cpu.R[2] = c8.Platform.StackTop - uint16(c8.SP)*2 - 1
+		}
+		cpu.R[3] = addr
+		cpu.R[5] = c8.PC
+		cpu.R[0xA] = c8.I
+
+		if opts.MirrorV {
+			for i, val := range c8.V {
+				err := c8.Bus.Set8(opts.VRegisters+uint16(i), val)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for executed := 0; cpu.P != 4; executed++ {
+			if opts.MaxInstructions > 0 && executed >= opts.MaxInstructions {
+				return fmt.Errorf("machine code routine at %04X did not return after %v instructions", addr, executed)
+			}
+
+			err := cpu.Step()
+			if err != nil {
+				return fmt.Errorf("machine code routine at %04X: %w", addr, err)
+			}
+		}
+
+		if opts.MirrorV {
+			for i := range c8.V {
+				val, err := c8.Bus.Get8(opts.VRegisters + uint16(i))
+				if err != nil {
+					return err
+				}
+				c8.V[i] = val
+			}
+		}
+
+		if c8.Platform.MemoryStack {
+			used := int(c8.Platform.StackTop) - 1 - int(cpu.R[2])
+			if used < 0 || used%2 != 0 || used/2 > c8.Platform.StackDepth {
+				return fmt.Errorf("machine code routine at %04X left R2 at %04X, off the CHIP-8 stack", addr, cpu.R[2])
+			}
+			c8.SP = uint8(used / 2)
+		}
+
+		c8.PC = cpu.R[5]
+		c8.I = cpu.R[0xA]
+		if c8.Timing != nil {
+			c8.Cycles += cpu.Cycles
+		}
+
+		return nil
+	}
+}
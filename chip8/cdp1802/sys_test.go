@@ -0,0 +1,144 @@
+package cdp1802
+
+import (
+	"testing"
+
+	"github.com/frasmataz/go-chip8/chip8"
+)
+
+func newHybridCpu(t *testing.T, rom []byte, opts SysOptions) *chip8.Cpu {
+	t.Helper()
+
+	cpu, err := chip8.NewCpuWithROM(chip8.PlatformVIP, rom)
+	if err != nil {
+		t.Fatalf("NewCpuWithROM() error = %v", err)
+	}
+	cpu.Sys = NewSysHandler(opts)
+	return cpu
+}
+
+func TestSysHandler(t *testing.T) {
+	rom := []byte{
+		0x02, 0x06, // 200: SYS 0x206
+		0x61, 0x01, // 202: LD V1, 0x01
+		0x12, 0x04, // 204: JP 0x204
+		// 206: machine code - V0 += 1 through its mirror at 0xEF0, I = 0x345, then SEP R4
+		0xF8, 0x0E, 0xB6, // LDI 0x0E, PHI R6
+		0xF8, 0xF0, 0xA6, // LDI 0xF0, PLO R6
+		0x06, 0xFC, 0x01, 0x56, // LDN R6, ADI 0x01, STR R6
+		0xF8, 0x03, 0xBA, // LDI 0x03, PHI RA
+		0xF8, 0x45, 0xAA, // LDI 0x45, PLO RA
+		0xD4, // SEP R4
+	}
+
+	cpu := newHybridCpu(t, rom, VIPSysOptions)
+	cpu.V[0] = 0x41
+
+	for range 2 {
+		if err := cpu.Tick(); err != nil {
+			t.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	}
+
+	if cpu.V[0] != 0x42 || cpu.V[1] != 0x01 || cpu.I != 0x345 || cpu.PC != 0x204 {
+		t.Errorf("after SYS and one instruction V0 = %02X, V1 = %02X, I = %04X, PC = %04X, want 42, 01, 0345, 0204",
+			cpu.V[0], cpu.V[1], cpu.I, cpu.PC)
+	}
+}
+
+// Pushes from the routine go below the CHIP-8 return stack, not over the return addresses on it
+func TestSysHandler_MemoryStack(t *testing.T) {
+	tests := map[string]struct {
+		code    []byte
+		wantSP  uint8
+		wantErr bool
+	}{
+		"balanced":   {code: []byte{0xF8, 0xAA, 0x73, 0x73, 0x60, 0x60}, wantSP: 1}, // LDI 0xAA, STXD, STXD, IRX, IRX
+		"popped":     {code: []byte{0x60, 0x60}, wantSP: 0},                         // IRX, IRX - drops the CHIP-8 return address
+		"misaligned": {code: []byte{0x73}, wantErr: true},                           // STXD
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rom := []byte{
+				0x22, 0x06, // 200: CALL 0x206
+				0x12, 0x02, // 202: JP 0x202
+				0x00, 0x00,
+				0x02, 0x0A, // 206: SYS 0x20A
+				0x00, 0xEE, // 208: RET
+			}
+			rom = append(rom, test.code...)
+			rom = append(rom, 0xD4) // SEP R4
+
+			cpu := newHybridCpu(t, rom, VIPSysOptions)
+			if err := cpu.Tick(); err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+
+			err := cpu.Tick()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("SYS error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if cpu.SP != test.wantSP {
+				t.Errorf("SYS left SP = %v, want %v", cpu.SP, test.wantSP)
+			}
+			if test.wantSP == 1 {
+				cpu.Tick()
+				if cpu.PC != 0x202 {
+					t.Errorf("RET after SYS jumped to %04X, want 0202", cpu.PC)
+				}
+			}
+		})
+	}
+}
+
+func TestSysHandler_ReturnViaRET(t *testing.T) {
+	rom := []byte{
+		0x02, 0x04, // 200: SYS 0x204
+		0x00, 0x00,
+		// 204: push X=2, P=4 and return through it, leaving R2 where it started
+		0x22,             // DEC R2
+		0xF8, 0x24, 0x73, // LDI 0x24, STXD
+		0x60, 0x70, // IRX, RET
+	}
+
+	cpu := newHybridCpu(t, rom, VIPSysOptions)
+	cpu.Timing = chip8.DefaultVIPTiming
+
+	if err := cpu.Tick(); err != nil {
+		t.Fatalf("Cpu.Tick() error = %v", err)
+	}
+	if cpu.PC != 0x202 {
+		t.Errorf("PC = %04X after SYS returning with RET, want 0202", cpu.PC)
+	}
+	if want := uint64(40 + 5*2); cpu.Cycles != want {
+		t.Errorf("Cpu.Cycles = %v after SYS, want %v including the routine's 1802 cycles", cpu.Cycles, want)
+	}
+}
+
+func TestSysHandler_NoReturn(t *testing.T) {
+	rom := []byte{
+		0x02, 0x04, // 200: SYS 0x204
+		0x00, 0x00,
+		0x30, 0x04, // 204: BR 0x04, forever
+	}
+
+	opts := VIPSysOptions
+	opts.MaxInstructions = 100
+	cpu := newHybridCpu(t, rom, opts)
+
+	if err := cpu.Tick(); err == nil {
+		t.Errorf("Cpu.Tick() error = nil for a routine that never returns")
+	}
+}
+
+func TestSysHandler_Idle(t *testing.T) {
+	cpu := newHybridCpu(t, []byte{0x02, 0x02, 0x00, 0x00}, VIPSysOptions) // SYS 0x202, IDL
+
+	if err := cpu.Tick(); err == nil {
+		t.Errorf("Cpu.Tick() error = nil for a routine that idles")
+	}
+}
@@ -0,0 +1,126 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestChip10_Display(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip10, nil)
+
+	if cpu.Display.Width() != 128 || cpu.Display.Height() != 64 {
+		t.Fatalf("display is %vx%v, want 128x64", cpu.Display.Width(), cpu.Display.Height())
+	}
+
+	cpu.Memory.Set8(0xFFF, 0x01)
+	if got, _ := cpu.Display.Get(127, 63); !got {
+		t.Errorf("Display.Get(127, 63) = false after writing the last byte of display memory")
+	}
+	if cpu.Memory.Memory[0xBFF] != 0x00 {
+		t.Errorf("display memory starts below %04X", PlatformChip10.DisplayBase)
+	}
+}
+
+func TestChip10_DRW(t *testing.T) {
+	tests := map[string]struct {
+		x, y  uint8
+		lit   [][2]uint
+		unlit [][2]uint
+	}{
+		"hi-res corner": {
+			x: 124, y: 59,
+			lit:   [][2]uint{{124, 59}, {127, 59}, {124, 63}},
+			unlit: [][2]uint{{123, 59}},
+		},
+		"wraps at 128x64": {
+			x: 130, y: 66,
+			lit:   [][2]uint{{2, 2}, {5, 6}},
+			unlit: [][2]uint{{66, 2}},
+		},
+		"clipped at right edge": {
+			x: 126, y: 0,
+			lit:   [][2]uint{{126, 0}, {127, 0}},
+			unlit: [][2]uint{{0, 0}, {0, 1}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip10, []byte{0xA0, 0x00, 0xD0, 0x15}) // I = font "0", DRW V0, V1, 5
+			cpu.V[0], cpu.V[1] = test.x, test.y
+
+			for range 2 {
+				err := cpu.Tick()
+				if err != nil {
+					t.Fatalf("Cpu.Tick() error = %v", err)
+				}
+			}
+
+			for _, pixel := range test.lit {
+				if got, _ := cpu.Display.Get(pixel[0], pixel[1]); !got {
+					t.Errorf("pixel %v unlit, want lit", pixel)
+				}
+			}
+			for _, pixel := range test.unlit {
+				if got, _ := cpu.Display.Get(pixel[0], pixel[1]); got {
+					t.Errorf("pixel %v lit, want unlit", pixel)
+				}
+			}
+		})
+	}
+}
+
+func TestChip10_CLS(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip10, []byte{0x00, 0xE0})
+	cpu.Display.Set(127, 63, true)
+	cpu.Display.Acknowledge()
+
+	err := cpu.Tick()
+	if err != nil {
+		t.Fatalf("Cpu.Tick() error = %v", err)
+	}
+
+	if got, _ := cpu.Display.Get(127, 63); got {
+		t.Errorf("Display.Get(127, 63) = true after CLS")
+	}
+	if want := (Rect{X: 120, Y: 63, W: 8, H: 1}); cpu.Display.Damage() != want {
+		t.Errorf("Display.Damage() = %+v after CLS, want %+v", cpu.Display.Damage(), want)
+	}
+}
+
+func TestChip10_State(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip10, nil)
+	cpu.Display.Set(100, 50, true)
+
+	restored := newPlatformCpu(t, PlatformChip10, nil)
+	err := restored.LoadState(cpu.SaveState())
+	if err != nil {
+		t.Fatalf("Cpu.LoadState() error = %v", err)
+	}
+	if got, _ := restored.Display.Get(100, 50); !got {
+		t.Errorf("Cpu.LoadState() did not restore pixel (100, 50)")
+	}
+
+	err = NewCpu().LoadState(cpu.SaveState())
+	if err == nil {
+		t.Errorf("Cpu.LoadState() of a 128x64 state into a 64x32 Cpu error = %v, wantErr true", err)
+	}
+}
+
+func TestChip10_FrameBuffer(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip10, nil)
+	fb := NewFrameBuffer()
+
+	cpu.Display.Set(127, 63, true)
+	fb.Publish(cpu.Display)
+
+	frame := fb.Snapshot()
+	if frame.Width() != 128 || frame.Height() != 64 {
+		t.Errorf("Frame is %vx%v, want 128x64", frame.Width(), frame.Height())
+	}
+	if want := (Rect{W: 128, H: 64}); frame.Damage != want {
+		t.Errorf("Frame.Damage = %+v after the display size changed, want %+v", frame.Damage, want)
+	}
+	if got, _ := frame.Get(127, 63); !got {
+		t.Errorf("Frame.Get(127, 63) = false, want the lit pixel")
+	}
+}
@@ -0,0 +1,78 @@
+package chip8
+
+// CHIP-8E, the VIP interpreter revision that adds a greater-than skip, register range stores and loads,
+// relative jumps and a stop instruction
+var chip8EExtensions = []Extension{
+	{Name: "CHIP-8E", Mask: 0xFFFF, Value: 0x00ED, Mnemonic: "STOP", Handler: chip8ESTOP},
+	{Name: "CHIP-8E", Mask: 0xF00F, Value: 0x5001, Priority: PriorityFallback, Mnemonic: "SGT", Format: "V%[1]X, V%[2]X", Handler: chip8ESGT},
+	{Name: "CHIP-8E", Mask: 0xF00F, Value: 0x5002, Mnemonic: "LD", Format: "[I], V%[1]X-V%[2]X", Handler: chip8ESTR},
+	{Name: "CHIP-8E", Mask: 0xF00F, Value: 0x5003, Mnemonic: "LD", Format: "V%[1]X-V%[2]X, [I]", Handler: chip8ELDR},
+	{Name: "CHIP-8E", Mask: 0xFF00, Value: 0xBB00, Mnemonic: "JB", Format: "0x%02[4]X", Handler: chip8EJB},
+	{Name: "CHIP-8E", Mask: 0xFF00, Value: 0xBF00, Mnemonic: "JF", Format: "0x%02[4]X", Handler: chip8EJF},
+}
+
+// Stops the program by running again forever. Timers and the display carry on.
+func chip8ESTOP(cpu *Cpu, inst Instruction) error {
+	cpu.PC -= 2
+	return nil
+}
+
+// Skips the next instruction if Vx > Vy
+func chip8ESGT(cpu *Cpu, inst Instruction) error {
+	if cpu.V[inst.X] > cpu.V[inst.Y] {
+		cpu.PC += 2
+	}
+	return nil
+}
+
+// Registers Vx to Vy in order, counting down if y < x
+func chip8ERange(inst Instruction) []uint8 {
+	var regs []uint8
+	step := uint8(1)
+	if inst.Y < inst.X {
+		step = 0xFF
+	}
+	for reg := inst.X; ; reg += step {
+		regs = append(regs, reg)
+		if reg == inst.Y {
+			return regs
+		}
+	}
+}
+
+// Stores Vx to Vy at I, leaving I just past them
+func chip8ESTR(cpu *Cpu, inst Instruction) error {
+	for _, reg := range chip8ERange(inst) {
+		err := cpu.Bus.Set8(cpu.I, cpu.V[reg])
+		if err != nil {
+			return err
+		}
+		cpu.I++
+	}
+	return nil
+}
+
+// Loads Vx to Vy from I, leaving I just past them
+func chip8ELDR(cpu *Cpu, inst Instruction) error {
+	for _, reg := range chip8ERange(inst) {
+		val, err := cpu.Bus.Get8(cpu.I)
+		if err != nil {
+			return err
+		}
+		cpu.V[reg] = val
+		cpu.I++
+	}
+	return nil
+}
+
+// Jumps back NN bytes from the following instruction
+func chip8EJB(cpu *Cpu, inst Instruction) error {
+	cpu.PC -= uint16(inst.NN)
+	return nil
+}
+
+// Jumps forward NN bytes from the following instruction
+func chip8EJF(cpu *Cpu, inst Instruction) error {
+	cpu.PC += uint16(inst.NN)
+	return nil
+}
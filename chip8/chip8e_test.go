@@ -0,0 +1,145 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestChip8E_STOP(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8E, []byte{0x00, 0xED})
+	cpu.DT = 5
+
+	scheduler := NewScheduler(cpu)
+	err := scheduler.RunFrames(2)
+	if err != nil {
+		t.Fatalf("Scheduler.RunFrames() error = %v", err)
+	}
+
+	if cpu.PC != 0x200 {
+		t.Errorf("PC = %04X after STOP, want 0200", cpu.PC)
+	}
+	if cpu.DT != 3 {
+		t.Errorf("DT = %v, want timers to run on while stopped", cpu.DT)
+	}
+}
+
+func TestChip8E_SGT(t *testing.T) {
+	tests := map[string]struct {
+		vx, vy uint8
+		want   uint16
+	}{
+		"greater": {vx: 0x10, vy: 0x0F, want: 0x204},
+		"equal":   {vx: 0x10, vy: 0x10, want: 0x202},
+		"less":    {vx: 0x0F, vy: 0x10, want: 0x202},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8E, []byte{0x51, 0x21}) // SGT V1, V2
+			cpu.V[1], cpu.V[2] = test.vx, test.vy
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+			if cpu.PC != test.want {
+				t.Errorf("PC = %04X, want %04X", cpu.PC, test.want)
+			}
+		})
+	}
+}
+
+func TestChip8E_STR(t *testing.T) {
+	tests := map[string]struct {
+		opcode uint16
+		want   []uint8
+	}{
+		"ascending":  {opcode: 0x5242, want: []uint8{0x22, 0x33, 0x44}},
+		"descending": {opcode: 0x5422, want: []uint8{0x44, 0x33, 0x22}},
+		"single":     {opcode: 0x5332, want: []uint8{0x33}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8E, []byte{uint8(test.opcode >> 8), uint8(test.opcode)})
+			cpu.V[2], cpu.V[3], cpu.V[4] = 0x22, 0x33, 0x44
+			cpu.I = 0x400
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+
+			for i, want := range test.want {
+				if got := cpu.Memory.Memory[0x400+i]; got != want {
+					t.Errorf("memory at %04X = %02X, want %02X", 0x400+i, got, want)
+				}
+			}
+			if want := uint16(0x400 + len(test.want)); cpu.I != want {
+				t.Errorf("I = %04X, want %04X", cpu.I, want)
+			}
+		})
+	}
+}
+
+func TestChip8E_LDR(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8E, []byte{0x5A, 0xC3}) // LD VA-VC, [I]
+	copy(cpu.Memory.Memory[0x400:], []uint8{0x0A, 0x0B, 0x0C, 0x0D})
+	cpu.I = 0x400
+
+	err := cpu.Tick()
+	if err != nil {
+		t.Fatalf("Cpu.Tick() error = %v", err)
+	}
+
+	if cpu.V[0xA] != 0x0A || cpu.V[0xB] != 0x0B || cpu.V[0xC] != 0x0C || cpu.V[0xD] != 0x00 {
+		t.Errorf("VA-VD = %02X, want 0A 0B 0C 00", cpu.V[0xA:0xE])
+	}
+	if cpu.I != 0x403 {
+		t.Errorf("I = %04X, want 0403", cpu.I)
+	}
+}
+
+func TestChip8E_Jumps(t *testing.T) {
+	tests := map[string]struct {
+		opcode uint16
+		want   uint16
+	}{
+		"back":    {opcode: 0xBB06, want: 0x1FC},
+		"forward": {opcode: 0xBF06, want: 0x208},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8E, []byte{uint8(test.opcode >> 8), uint8(test.opcode)})
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+			if cpu.PC != test.want {
+				t.Errorf("PC = %04X, want %04X", cpu.PC, test.want)
+			}
+		})
+	}
+}
+
+func TestChip8E_Decode(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8E, nil)
+
+	tests := map[uint16]string{
+		0x00ED: "STOP",
+		0x5121: "SGT V1, V2",
+		0x5242: "LD [I], V2-V4",
+		0x5AC3: "LD VA-VC, [I]",
+		0xBB06: "JB 0x06",
+		0xBF06: "JF 0x06",
+		0xB300: "JP V0, 0x300",
+		0x00EE: "RET",
+	}
+
+	for opcode, want := range tests {
+		if got := cpu.Decode(opcode).String(); got != want {
+			t.Errorf("Cpu.Decode(%04X).String() = %q, want %q", opcode, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package chip8
+
+// CHIP-8X, for the VIP with the VP-590 colour board and a second VP-580 keypad. It replaces JP V0 with
+// colour zone fills, and adds a background colour step, packed colour addition and second keypad skips.
+var chip8XExtensions = []Extension{
+	{Name: "CHIP-8X", Mask: 0xFFFF, Value: 0x02A0, Mnemonic: "BGCOL", Handler: chip8XBGCOL},
+	{Name: "CHIP-8X", Mask: 0xF00F, Value: 0x5001, Priority: PriorityFallback, Mnemonic: "ADD", Format: "V%[1]X, V%[2]X", Handler: chip8XADDN},
+	{Name: "CHIP-8X", Mask: 0xF000, Value: 0xB000, Mnemonic: "COL", Format: "V%[1]X, V%[2]X, %[3]d", Handler: chip8XCOL},
+	{Name: "CHIP-8X", Mask: 0xF0FF, Value: 0xE0F2, Priority: PriorityFallback, Mnemonic: "SKP2", Format: "V%[1]X", Handler: chip8XSKP2},
+	{Name: "CHIP-8X", Mask: 0xF0FF, Value: 0xE0F5, Priority: PriorityFallback, Mnemonic: "SKNP2", Format: "V%[1]X", Handler: chip8XSKNP2},
+}
+
+// Steps the background colour through blue, black, green and red
+func chip8XBGCOL(cpu *Cpu, inst Instruction) error {
+	cpu.Display.cycleBackground()
+	return nil
+}
+
+// Adds Vy to Vx as two packed fields, bits 4-6 and bits 0-2, each wrapping without carrying into the other
+func chip8XADDN(cpu *Cpu, inst Instruction) error {
+	vx, vy := cpu.V[inst.X], cpu.V[inst.Y]
+	cpu.V[inst.X] = ((vx&0x70)+(vy&0x70))&0x70 | ((vx&0x07)+(vy&0x07))&0x07
+	return nil
+}
+
+// Colours zones with Vy. Vx holds the left zone column in its low nibble and the extra columns to its right
+// in its high nibble. With N = 0, V(x+1) holds the top zone row and extra rows likewise, in zone rows of 4
+// pixels; otherwise V(x+1) is the top pixel row and N rows are coloured.
+func chip8XCOL(cpu *Cpu, inst Instruction) error {
+	horizontal, vertical := cpu.V[inst.X], cpu.V[(inst.X+1)&0xF]
+	col, cols := uint(horizontal&0xF), uint(horizontal>>4)+1
+
+	row, rows := uint(vertical), uint(inst.N)
+	if inst.N == 0 {
+		row, rows = uint(vertical&0xF)*4, (uint(vertical>>4)+1)*4
+	}
+
+	cpu.Display.fillColour(col, cols, row, rows, Colour(cpu.V[inst.Y]))
+	return nil
+}
+
+// Skips the next instruction if key Vx is held on the second keypad
+func chip8XSKP2(cpu *Cpu, inst Instruction) error {
+	pressed, err := cpu.Keypad.IsPressedPad(1, cpu.V[inst.X]&0xF)
+	if err != nil {
+		return err
+	}
+	if pressed {
+		cpu.PC += 2
+	}
+	return nil
+}
+
+func chip8XSKNP2(cpu *Cpu, inst Instruction) error {
+	pressed, err := cpu.Keypad.IsPressedPad(1, cpu.V[inst.X]&0xF)
+	if err != nil {
+		return err
+	}
+	if !pressed {
+		cpu.PC += 2
+	}
+	return nil
+}
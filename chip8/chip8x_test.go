@@ -0,0 +1,177 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestChip8X_BGCOL(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8X, []byte{0x02, 0xA0, 0x02, 0xA0})
+	cpu.Display.Acknowledge()
+
+	cpu.Tick()
+	if got := cpu.Display.Colours().Background(); got != ColourBlack {
+		t.Errorf("background after one BGCOL = %v, want black", got)
+	}
+	if cpu.Display.Damage().Empty() {
+		t.Errorf("BGCOL did not mark the display changed")
+	}
+
+	cpu.Tick()
+	if got := cpu.Display.Colours().Background(); got != ColourGreen {
+		t.Errorf("background after two BGCOL = %v, want green", got)
+	}
+}
+
+func TestChip8X_ADDN(t *testing.T) {
+	tests := map[string]struct {
+		vx, vy uint8
+		want   uint8
+	}{
+		"no wrap":    {vx: 0x12, vy: 0x21, want: 0x33},
+		"low wraps":  {vx: 0x06, vy: 0x03, want: 0x01},
+		"high wraps": {vx: 0x50, vy: 0x40, want: 0x10},
+		"high bits":  {vx: 0x88, vy: 0x88, want: 0x00},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8X, []byte{0x51, 0x21}) // ADD V1, V2
+			cpu.V[1], cpu.V[2] = test.vx, test.vy
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+			if cpu.V[1] != test.want {
+				t.Errorf("V1 = %02X, want %02X", cpu.V[1], test.want)
+			}
+			if cpu.V[0xF] != 0 {
+				t.Errorf("VF = %02X, want unchanged", cpu.V[0xF])
+			}
+		})
+	}
+}
+
+func TestChip8X_COL(t *testing.T) {
+	tests := map[string]struct {
+		opcode     uint16
+		horizontal uint8
+		vertical   uint8
+		coloured   [][2]uint
+		plain      [][2]uint
+	}{
+		"zones": {
+			opcode:     0xB350, // COL V3, V5, 0
+			horizontal: 0x12,   // Columns 2 and 3
+			vertical:   0x01,   // Zone row 1, pixel rows 4 to 7
+			coloured:   [][2]uint{{16, 4}, {31, 7}},
+			plain:      [][2]uint{{15, 4}, {32, 4}, {16, 3}, {16, 8}},
+		},
+		"pixel rows": {
+			opcode:     0xB352, // COL V3, V5, 2
+			horizontal: 0x00,
+			vertical:   0x05,
+			coloured:   [][2]uint{{0, 5}, {7, 6}},
+			plain:      [][2]uint{{8, 5}, {0, 4}, {0, 7}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8X, []byte{uint8(test.opcode >> 8), uint8(test.opcode)})
+			cpu.V[3], cpu.V[4], cpu.V[5] = test.horizontal, test.vertical, uint8(ColourAqua)
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+
+			for _, pixel := range test.coloured {
+				if got, _ := cpu.Display.Colours().Foreground(pixel[0], pixel[1]); got != ColourAqua {
+					t.Errorf("zone colour at %v = %v, want aqua", pixel, got)
+				}
+			}
+			for _, pixel := range test.plain {
+				if got, _ := cpu.Display.Colours().Foreground(pixel[0], pixel[1]); got != ColourRed {
+					t.Errorf("zone colour at %v = %v, want the default red", pixel, got)
+				}
+			}
+			if cpu.PC != PlatformChip8X.Origin+2 {
+				t.Errorf("PC = %04X, COL jumped like JP V0", cpu.PC)
+			}
+		})
+	}
+}
+
+func TestChip8X_SKP2(t *testing.T) {
+	tests := map[string]struct {
+		opcode uint16
+		pad    int
+		want   uint16
+	}{
+		"SKP2 held":              {opcode: 0xE6F2, pad: 1, want: 0x304},
+		"SKP2 held on first pad": {opcode: 0xE6F2, pad: 0, want: 0x302},
+		"SKNP2 held":             {opcode: 0xE6F5, pad: 1, want: 0x302},
+		"SKNP2 not held":         {opcode: 0xE6F5, pad: 0, want: 0x304},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformChip8X, []byte{uint8(test.opcode >> 8), uint8(test.opcode)})
+			cpu.V[6] = 0xB
+			cpu.Keypad.PressPad(test.pad, 0xB)
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+			if cpu.PC != test.want {
+				t.Errorf("PC = %04X, want %04X", cpu.PC, test.want)
+			}
+		})
+	}
+}
+
+func TestChip8X_Decode(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8X, nil)
+
+	tests := map[uint16]string{
+		0x02A0: "BGCOL",
+		0x5121: "ADD V1, V2",
+		0xB350: "COL V3, V5, 0",
+		0xE6F2: "SKP2 V6",
+		0xE6F5: "SKNP2 V6",
+		0x5120: "SE V1, V2",
+		0xE69E: "SKP V6",
+	}
+
+	for opcode, want := range tests {
+		if got := cpu.Decode(opcode).String(); got != want {
+			t.Errorf("Cpu.Decode(%04X).String() = %q, want %q", opcode, got, want)
+		}
+	}
+
+	vip, _ := NewCpuWithPlatform(PlatformVIP)
+	if vip.Decode(0xB350).Op != OpJP_v0_addr {
+		t.Errorf("CHIP-8X opcodes decoded on a plain VIP")
+	}
+}
+
+func TestChip8X_State(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformChip8X, nil)
+	cpu.Display.Colours().Fill(2, 3, 5, 7, ColourViolet)
+	cpu.Display.Colours().CycleBackground()
+
+	restored := newPlatformCpu(t, PlatformChip8X, nil)
+	err := restored.LoadState(cpu.SaveState())
+	if err != nil {
+		t.Fatalf("Cpu.LoadState() error = %v", err)
+	}
+
+	if *restored.Display.Colours() != *cpu.Display.Colours() {
+		t.Errorf("Cpu.LoadState() did not restore the colour zones")
+	}
+	if restored.StateHash() != cpu.StateHash() {
+		t.Errorf("Cpu.StateHash() differs after LoadState")
+	}
+}
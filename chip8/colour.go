@@ -0,0 +1,140 @@
+package chip8
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Colour as produced by the VP-590 colour board - bit 0 is red, bit 1 blue and bit 2 green
+type Colour uint8
+
+const (
+	ColourBlack Colour = iota
+	ColourRed
+	ColourBlue
+	ColourViolet
+	ColourGreen
+	ColourYellow
+	ColourAqua
+	ColourWhite
+)
+
+var colourRGBA = [8]color.RGBA{
+	ColourBlack:  {0x00, 0x00, 0x00, 0xFF},
+	ColourRed:    {0xFF, 0x00, 0x00, 0xFF},
+	ColourBlue:   {0x00, 0x00, 0xFF, 0xFF},
+	ColourViolet: {0xFF, 0x00, 0xFF, 0xFF},
+	ColourGreen:  {0x00, 0xFF, 0x00, 0xFF},
+	ColourYellow: {0xFF, 0xFF, 0x00, 0xFF},
+	ColourAqua:   {0x00, 0xFF, 0xFF, 0xFF},
+	ColourWhite:  {0xFF, 0xFF, 0xFF, 0xFF},
+}
+
+func (colour Colour) RGBA() color.RGBA {
+	return colourRGBA[colour&0x7]
+}
+
+// Order the background steps through, starting from blue
+var backgroundCycle = [4]Colour{ColourBlue, ColourBlack, ColourGreen, ColourRed}
+
+// Colour attribute zones are 8 pixels wide, and as short as a single row. They cover the standard display size,
+// as colour platforms have no others.
+const zoneColumns = width / 8
+const zoneRows = height
+
+// ColourZones holds the colour attributes laid over the monochrome display: one background colour for
+// unlit pixels, and a foreground colour for the lit pixels of each 8x1 zone
+type ColourZones struct {
+	background int // Index into backgroundCycle
+	zones      [zoneRows][zoneColumns]Colour
+}
+
+// Zones start red over a blue background
+func NewColourZones() *ColourZones {
+	zones := new(ColourZones)
+	for row := range zones.zones {
+		for col := range zones.zones[row] {
+			zones.zones[row][col] = ColourRed
+		}
+	}
+	return zones
+}
+
+func (zones *ColourZones) Background() Colour {
+	return backgroundCycle[zones.background]
+}
+
+// Steps the background to the next colour in its cycle
+func (zones *ColourZones) CycleBackground() {
+	zones.background = (zones.background + 1) % len(backgroundCycle)
+}
+
+// Foreground colour of the zone holding pixel (x, y)
+func (zones *ColourZones) Foreground(x uint, y uint) (Colour, error) {
+	if x >= width || y >= height {
+		return 0, fmt.Errorf("pixel coordinate out of range: x: %v, y: %v", x, y)
+	}
+
+	return zones.zones[y][x/8], nil
+}
+
+// Sets the foreground of the zones in columns col to col+cols-1 and pixel rows row to row+rows-1,
+// clipped to the display
+func (zones *ColourZones) Fill(col uint, cols uint, row uint, rows uint, colour Colour) {
+	for y := row; y < min(row+rows, zoneRows); y++ {
+		for x := col; x < min(col+cols, zoneColumns); x++ {
+			zones.zones[y][x] = colour & 0x7
+		}
+	}
+}
+
+// Colour attributes, or nil for a monochrome display
+func (display *Display) Colours() *ColourZones {
+	return display.colours
+}
+
+// Colour pixel (x, y) is shown in - white on black for a monochrome display
+func (display *Display) colourAt(x uint, y uint) Colour {
+	if display.colours == nil {
+		if display.pixel(x, y) {
+			return ColourWhite
+		}
+		return ColourBlack
+	}
+
+	if display.pixel(x, y) {
+		return display.colours.zones[y][x/8]
+	}
+	return display.colours.Background()
+}
+
+// Renders the display one image pixel per display pixel, in colour where the display has colour zones.
+// Encode it with image/png for screenshots.
+func (display *Display) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(display.width), int(display.height)))
+
+	for y := range display.height {
+		for x := range display.width {
+			img.SetRGBA(int(x), int(y), display.colourAt(x, y).RGBA())
+		}
+	}
+
+	return img
+}
+
+// Fills colour zones as ColourZones.Fill, marking the pixels they cover as changed
+func (display *Display) fillColour(col uint, cols uint, row uint, rows uint, colour Colour) {
+	display.colours.Fill(col, cols, row, rows, colour)
+
+	col, row = min(col, zoneColumns), min(row, zoneRows)
+	cols, rows = min(cols, zoneColumns-col), min(rows, zoneRows-row)
+	for y := row; y < row+rows; y++ {
+		display.markDirty(col*8, y, cols*8)
+	}
+}
+
+func (display *Display) cycleBackground() {
+	display.colours.CycleBackground()
+	display.markAllDirty()
+}
@@ -0,0 +1,94 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColourZones_Fill(t *testing.T) {
+	tests := map[string]struct {
+		col, cols, row, rows uint
+		x, y                 uint
+		want                 Colour
+	}{
+		"inside":         {col: 1, cols: 2, row: 4, rows: 4, x: 16, y: 7, want: ColourGreen},
+		"left of fill":   {col: 1, cols: 2, row: 4, rows: 4, x: 7, y: 4, want: ColourRed},
+		"below fill":     {col: 1, cols: 2, row: 4, rows: 4, x: 8, y: 8, want: ColourRed},
+		"clipped right":  {col: 7, cols: 4, row: 0, rows: 1, x: 63, y: 0, want: ColourGreen},
+		"clipped bottom": {col: 0, cols: 1, row: 30, rows: 8, x: 0, y: 31, want: ColourGreen},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			zones := NewColourZones()
+			zones.Fill(test.col, test.cols, test.row, test.rows, ColourGreen)
+
+			got, err := zones.Foreground(test.x, test.y)
+			if err != nil {
+				t.Fatalf("ColourZones.Foreground() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("ColourZones.Foreground(%v, %v) = %v, want %v", test.x, test.y, got, test.want)
+			}
+		})
+	}
+
+	if _, err := NewColourZones().Foreground(width, 0); err == nil {
+		t.Errorf("ColourZones.Foreground(%v, 0) error = %v, wantErr true", width, err)
+	}
+}
+
+func TestColourZones_CycleBackground(t *testing.T) {
+	zones := NewColourZones()
+
+	var got []Colour
+	for range 5 {
+		got = append(got, zones.Background())
+		zones.CycleBackground()
+	}
+
+	want := []Colour{ColourBlue, ColourBlack, ColourGreen, ColourRed, ColourBlue}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ColourZones.Background() = %v after %v steps, want %v", got[i], i, want[i])
+		}
+	}
+}
+
+func TestDisplay_Image(t *testing.T) {
+	display := NewDisplay()
+	display.Set(3, 2, true)
+
+	img := display.Image()
+	if got := img.RGBAAt(3, 2); got != ColourWhite.RGBA() {
+		t.Errorf("monochrome lit pixel = %v, want white", got)
+	}
+	if got := img.RGBAAt(0, 0); got != ColourBlack.RGBA() {
+		t.Errorf("monochrome unlit pixel = %v, want black", got)
+	}
+
+	display.colours = NewColourZones()
+	display.colours.Fill(0, 1, 2, 1, ColourYellow)
+
+	img = display.Image()
+	if got := img.RGBAAt(3, 2); got != ColourYellow.RGBA() {
+		t.Errorf("lit pixel in a yellow zone = %v, want yellow", got)
+	}
+	if got := img.RGBAAt(0, 0); got != ColourBlue.RGBA() {
+		t.Errorf("unlit pixel = %v, want the blue background", got)
+	}
+}
+
+func TestPrintFrame_Colour(t *testing.T) {
+	display := NewDisplay()
+	display.colours = NewColourZones()
+	display.Set(0, 0, true)
+
+	row := strings.Split(display.PrintFrame(), "\n")[1]
+	if !strings.HasPrefix(row, "\x1b[38;2;255;0;0m██\x1b[38;2;0;0;255m██") {
+		t.Errorf("PrintFrame() first row = %q, want a red then a blue pixel", row)
+	}
+	if !strings.HasSuffix(row, "\x1b[0m") {
+		t.Errorf("PrintFrame() row does not reset the colour")
+	}
+}
@@ -5,200 +5,391 @@ import (
 	"strings"
 )
 
-const debugLog = true
-const StackSize = 0x10
+const debugLog = false
+const StackSize = 0x10 // Default CHIP-8 stack depth - see Platform for other layouts
 
 type Cpu struct {
-	V     [0x10]uint8       // General-purpose 8-bit registers V0 - VF
-	I     uint16            // 16-bit register, used to hold memory addresses
-	PC    uint16            // Program counter - 16-bit
-	SP    uint8             // Stack pointer - 8-bit
-	DT    uint8             // Delay timer - 8-bit - dec at 60Hz when non-zero
-	ST    uint8             // Sound timer - 8-bit - dec at 60Hz when non-zero
-	Stack [StackSize]uint16 // Stack - 16 16-bit values
-
-	Memory  *Memory
+	V     [0x10]uint8 // General-purpose 8-bit registers V0 - VF
+	I     uint16      // 16-bit register, used to hold memory addresses
+	PC    uint16      // Program counter - 16-bit
+	SP    uint8       // Stack pointer - 8-bit
+	DT    uint8       // Delay timer - 8-bit - dec at 60Hz when non-zero
+	ST    uint8       // Sound timer - 8-bit - dec at 60Hz when non-zero
+	Stack []uint16    // Stack - Platform.StackDepth 16-bit values, or nil when the stack is in Memory
+
+	Platform Platform // Memory layout the Cpu was constructed with
+
+	Memory  *Memory // Backing RAM
+	Bus     Bus     // All memory traffic from instructions goes through this - the Memory by default
 	Display *Display
+	Keypad  *Keypad
+	Trace   *Trace      // Optional - records each executed instruction when set
+	Sys     SysHandler  // Optional - runs 0nnn machine-code calls, which are ignored when nil
+	Timing  TimingModel // Optional - when set, Tick adds each instruction's cost to Cycles
+	Cycles  uint64      // Machine cycles elapsed under Timing
+
+	decodeCache  *decodeCache
+	observers    *observers  // Nil until AddObserver
+	extensions   *Extensions // Nil until AddExtension
+	extensionGen uint64      // Counts changes to extensions, so decoded code can be dropped
 }
 
 func NewCpu() *Cpu {
-	cpu := new(Cpu)
-	cpu.Memory = NewMemory()
-	cpu.Display = NewDisplay()
-	cpu.PC = 0x200
+	cpu, _ := NewCpuWithPlatform(PlatformChip8)
 	return cpu
 }
 
+func NewCpuWithPlatform(platform Platform) (*Cpu, error) {
+	err := platform.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	cpu := new(Cpu)
+	cpu.Platform = platform
+	if !platform.MemoryStack {
+		cpu.Stack = make([]uint16, platform.StackDepth)
+	}
+	cpu.Memory = newMemory(platform.MemorySize, platform.FontAddr)
+	cpu.Bus = cpu.Memory
+	w, h := platform.displaySize()
+	if platform.MemoryDisplay {
+		cpu.Display, err = NewMappedDisplaySize(cpu.Memory, platform.DisplayBase, w, h)
+	} else {
+		cpu.Display, err = NewDisplaySize(w, h)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if platform.Variant == VariantChip8X {
+		cpu.Display.colours = NewColourZones()
+	}
+	cpu.Keypad = NewKeypad()
+	cpu.PC = platform.Origin
+
+	for _, ext := range platform.Variant.extensions() {
+		_, err = cpu.AddExtension(ext)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cpu, nil
+}
+
+// Cpu for platform with rom loaded at its origin
+func NewCpuWithROM(platform Platform, rom []byte) (*Cpu, error) {
+	cpu, err := NewCpuWithPlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cpu.LoadROM(rom)
+	if err != nil {
+		return nil, err
+	}
+	return cpu, nil
+}
+
+func (cpu *Cpu) LoadROM(rom []byte) error {
+	origin := int(cpu.Platform.Origin)
+	if len(rom) > len(cpu.Memory.Memory)-origin {
+		return fmt.Errorf("ROM too large: %v bytes, max %v", len(rom), len(cpu.Memory.Memory)-origin)
+	}
+
+	copy(cpu.Memory.Memory[origin:], rom)
+	cpu.Memory.reloaded()
+
+	return nil
+}
+
 func (cpu *Cpu) Tick() error {
-	// Fetch
-	opcode, err := cpu.Memory.Get16(cpu.PC)
+	// Fetch and decode
+	inst, err := cpu.fetch()
 	if err != nil {
 		return err
 	}
 
-	cpu.PC += 2
+	return cpu.run(inst)
+}
 
-	// Decode
-	err = decodeAndExecute(opcode, cpu)
-
-	return err
-}
-
-func decodeAndExecute(opcode uint16, cpu *Cpu) error {
-	if opcode&0xF0FF == 0x00E0 {
-		return cpu.CLS()
-	} else if opcode&0xF0FF == 0x00EE {
-		return cpu.RET()
-	} else if opcode&0xF000 == 0x1000 {
-		return cpu.JP(opcode)
-	} else if opcode&0xF000 == 0x2000 {
-		return cpu.CALL(opcode)
-	} else if opcode&0xF000 == 0x3000 {
-		return cpu.SE_v_byte(opcode)
-	} else if opcode&0xF000 == 0x4000 {
-		return cpu.SNE_v_byte(opcode)
-	} else if opcode&0xF00F == 0x5000 {
-		return cpu.SE_v1_v2(opcode)
-	} else if opcode&0xF000 == 0x6000 {
-		return cpu.LD_v_byte(opcode)
-	} else if opcode&0xF000 == 0x7000 {
-		return cpu.ADD_v_byte(opcode)
-	} else if opcode&0xF00F == 0x8000 {
-		return cpu.LD_v1_v2(opcode)
-	} else if opcode&0xF00F == 0x8001 {
-		return cpu.OR_v1_v2(opcode)
-	} else if opcode&0xF00F == 0x8002 {
-		return cpu.AND_v1_v2(opcode)
-	} else if opcode&0xF00F == 0x8003 {
-		return cpu.XOR_v1_v2(opcode)
-	} else if opcode&0xF00F == 0x8004 {
-		return cpu.ADD_v1_v2(opcode)
+// Tick's execute step, for inst just fetched from PC
+func (cpu *Cpu) run(inst Instruction) error {
+	if cpu.Trace != nil {
+		cpu.Trace.recordInstruction(cpu.PC, inst)
 	}
-	return nil
+
+	if cpu.Timing != nil {
+		cpu.Cycles += cpu.Timing.Cost(cpu, inst)
+	}
+
+	if cpu.observers != nil {
+		return cpu.executeObserved(inst)
+	}
+
+	cpu.PC += 2
+
+	return execute(inst, cpu)
 }
 
-func (cpu *Cpu) CLS() error {
+// Whether every instruction must go through Tick - true with a Trace, timing model, observers, custom Bus or
+// execute hooks attached. Faster execution paths such as BlockEngine fall back to Tick when it is.
+func (cpu *Cpu) NeedsTick() bool {
+	return cpu.Trace != nil || cpu.Timing != nil || cpu.observers != nil || cpu.Bus != Bus(cpu.Memory) || cpu.Memory.hasExecuteHooks()
+}
+
+type opHandler func(cpu *Cpu, inst Instruction) error
+
+// Runs the machine-code routine at addr for 0nnn. PC has already advanced past the SYS instruction.
+type SysHandler func(cpu *Cpu, addr uint16) error
+
+// Indexed by Op - ops without a handler are ignored
+var opHandlers = [opCount]opHandler{
+	OpSYS:        (*Cpu).SYS,
+	OpCLS:        func(cpu *Cpu, _ Instruction) error { return cpu.CLS() },
+	OpRET:        func(cpu *Cpu, _ Instruction) error { return cpu.RET() },
+	OpJP:         (*Cpu).JP,
+	OpCALL:       (*Cpu).CALL,
+	OpSE_v_byte:  (*Cpu).SE_v_byte,
+	OpSNE_v_byte: (*Cpu).SNE_v_byte,
+	OpSE_v1_v2:   (*Cpu).SE_v1_v2,
+	OpLD_v_byte:  (*Cpu).LD_v_byte,
+	OpADD_v_byte: (*Cpu).ADD_v_byte,
+	OpLD_v1_v2:   (*Cpu).LD_v1_v2,
+	OpOR_v1_v2:   (*Cpu).OR_v1_v2,
+	OpAND_v1_v2:  (*Cpu).AND_v1_v2,
+	OpXOR_v1_v2:  (*Cpu).XOR_v1_v2,
+	OpADD_v1_v2:  (*Cpu).ADD_v1_v2,
+	OpDRW:        (*Cpu).DRW,
+	OpLD_v_k:     (*Cpu).LD_v_k,
+	OpLD_dt_v:    (*Cpu).LD_dt_v,
+	OpLD_st_v:    (*Cpu).LD_st_v,
+}
+
+func execute(inst Instruction, cpu *Cpu) error {
 	if debugLog {
-		fmt.Printf("CLS\n")
+		fmt.Printf("%v\n", inst)
 	}
 
-	cpu.Display = NewDisplay()
-	return nil
+	if inst.Ext != nil {
+		return inst.Ext.Handler(cpu, inst)
+	}
+
+	handler := opHandlers[inst.Op]
+	if handler == nil {
+		return nil
+	}
+
+	return handler(cpu, inst)
 }
 
-func (cpu *Cpu) RET() error {
-	if debugLog {
-		fmt.Printf("RET\n")
+func (cpu *Cpu) SYS(inst Instruction) error {
+	if cpu.Sys == nil {
+		return nil
 	}
+	return cpu.Sys(cpu, inst.NNN)
+}
 
+func (cpu *Cpu) CLS() error {
+	cpu.Display.Clear()
+	cpu.notifyClear()
+	return nil
+}
+
+func (cpu *Cpu) RET() error {
 	if cpu.SP == 0x00 {
 		return fmt.Errorf("stack underflow on RET - SP is 0x00 - cpu state: %v", cpu.GetPrettyCpuState())
 	}
 
 	cpu.SP--
+
+	if cpu.Platform.MemoryStack {
+		addr, err := cpu.Bus.Get16(cpu.stackAddr(cpu.SP))
+		if err != nil {
+			return err
+		}
+		cpu.PC = addr
+		return nil
+	}
+
 	cpu.PC = uint16(cpu.Stack[cpu.SP])
 	return nil
 }
 
-func (cpu *Cpu) JP(opcode uint16) error {
-	target := opcode & 0x0FFF
+func (cpu *Cpu) stackDepth() int {
+	if cpu.Platform.MemoryStack {
+		return cpu.Platform.StackDepth
+	}
+	return len(cpu.Stack)
+}
 
-	if debugLog {
-		fmt.Printf("JP %04X\n", target)
+// Address of stack slot i in memory-backed stack mode - slots are big-endian words growing down from StackTop,
+// as on the VIP where the first return address sits at ECE-ECF
+func (cpu *Cpu) stackAddr(i uint8) uint16 {
+	return cpu.Platform.StackTop - (uint16(i)+1)*2
+}
+
+// Stack contents, read from Memory in memory-backed stack mode without going through the Bus
+func (cpu *Cpu) StackEntries() []uint16 {
+	if !cpu.Platform.MemoryStack {
+		return cpu.Stack
+	}
+
+	entries := make([]uint16, cpu.Platform.StackDepth)
+	for i := range entries {
+		addr := cpu.stackAddr(uint8(i))
+		entries[i] = uint16(cpu.Memory.Memory[addr])<<8 | uint16(cpu.Memory.Memory[addr+1])
 	}
+	return entries
+}
+
+func (cpu *Cpu) JP(inst Instruction) error {
+	target := inst.NNN
 
-	if target > 0xFFE {
-		return fmt.Errorf("target out of range for JP: %04X, max: 0ffe", target)
+	if int(target) > len(cpu.Memory.Memory)-2 {
+		return fmt.Errorf("target out of range for JP: %04X, max: %04x", target, len(cpu.Memory.Memory)-2)
 	}
 
 	cpu.PC = target
 	return nil
 }
 
-func (cpu *Cpu) CALL(opcode uint16) error {
-	target := opcode & 0x0FFF
+func (cpu *Cpu) CALL(inst Instruction) error {
+	target := inst.NNN
 
-	if debugLog {
-		fmt.Printf("CALL %04X\n", target)
+	depth := cpu.stackDepth()
+	if int(cpu.SP) > depth-1 {
+		return fmt.Errorf("stack overflow on CALL - SP is > 0x%02X - cpu state: %v", depth-1, cpu.GetPrettyCpuState())
 	}
 
-	if cpu.SP > StackSize-1 {
-		return fmt.Errorf("stack overflow on CALL - SP is > 0x0F - cpu state: %v", cpu.GetPrettyCpuState())
+	if cpu.Platform.MemoryStack {
+		err := cpu.Bus.Set16(cpu.stackAddr(cpu.SP), cpu.PC)
+		if err != nil {
+			return err
+		}
+	} else {
+		cpu.Stack[cpu.SP] = cpu.PC
 	}
-
-	cpu.Stack[cpu.SP] = cpu.PC
 	cpu.SP++
 	cpu.PC = target
 
 	return nil
 }
 
-func (cpu *Cpu) SE_v_byte(opcode uint16) error {
-	if cpu.V[(opcode&0x0F00)>>8] == uint8(opcode&0x00FF) {
+func (cpu *Cpu) SE_v_byte(inst Instruction) error {
+	if cpu.V[inst.X] == inst.NN {
 		cpu.PC += 2
 	}
 	return nil
 }
 
-func (cpu *Cpu) SNE_v_byte(opcode uint16) error {
-	if cpu.V[(opcode&0x0F00)>>8] != uint8(opcode&0x00FF) {
+func (cpu *Cpu) SNE_v_byte(inst Instruction) error {
+	if cpu.V[inst.X] != inst.NN {
 		cpu.PC += 2
 	}
 	return nil
 }
 
-func (cpu *Cpu) SE_v1_v2(opcode uint16) error {
-	if cpu.V[(opcode&0x0F00)>>8] == cpu.V[(opcode&0x00F0)>>4] {
+func (cpu *Cpu) SE_v1_v2(inst Instruction) error {
+	if cpu.V[inst.X] == cpu.V[inst.Y] {
 		cpu.PC += 2
 	}
 	return nil
 }
 
-func (cpu *Cpu) LD_v_byte(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] = uint8(opcode & 0x00FF)
+func (cpu *Cpu) LD_v_byte(inst Instruction) error {
+	cpu.V[inst.X] = inst.NN
 	return nil
 }
 
-func (cpu *Cpu) ADD_v_byte(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] += uint8(opcode & 0x00FF)
+func (cpu *Cpu) ADD_v_byte(inst Instruction) error {
+	cpu.V[inst.X] += inst.NN
 	return nil
 }
 
-func (cpu *Cpu) LD_v1_v2(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] = cpu.V[(opcode&0x00F0)>>4]
+func (cpu *Cpu) LD_v1_v2(inst Instruction) error {
+	cpu.V[inst.X] = cpu.V[inst.Y]
 	return nil
 }
 
-func (cpu *Cpu) OR_v1_v2(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] = cpu.V[(opcode&0x0F00)>>8] | cpu.V[(opcode&0x00F0)>>4]
+func (cpu *Cpu) OR_v1_v2(inst Instruction) error {
+	cpu.V[inst.X] = cpu.V[inst.X] | cpu.V[inst.Y]
 	return nil
 }
 
-func (cpu *Cpu) AND_v1_v2(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] = cpu.V[(opcode&0x0F00)>>8] & cpu.V[(opcode&0x00F0)>>4]
+func (cpu *Cpu) AND_v1_v2(inst Instruction) error {
+	cpu.V[inst.X] = cpu.V[inst.X] & cpu.V[inst.Y]
 	return nil
 }
 
-func (cpu *Cpu) XOR_v1_v2(opcode uint16) error {
-	cpu.V[(opcode&0x0F00)>>8] = cpu.V[(opcode&0x0F00)>>8] ^ cpu.V[(opcode&0x00F0)>>4]
+func (cpu *Cpu) XOR_v1_v2(inst Instruction) error {
+	cpu.V[inst.X] = cpu.V[inst.X] ^ cpu.V[inst.Y]
 	return nil
 }
 
-func (cpu *Cpu) ADD_v1_v2(opcode uint16) error {
-	r1 := (opcode & 0x0F00) >> 8
-	r2 := (opcode & 0x00F0) >> 4
-	sum := uint16(cpu.V[r1]) + uint16(cpu.V[r2])
+func (cpu *Cpu) ADD_v1_v2(inst Instruction) error {
+	sum := uint16(cpu.V[inst.X]) + uint16(cpu.V[inst.Y])
 	carry := uint8(0x00)
 	if sum > 0xFF {
 		carry = 0x01
 	}
 
-	cpu.V[r1] = uint8(sum)
+	cpu.V[inst.X] = uint8(sum)
 	cpu.V[0xF] = carry
 
 	return nil
 }
 
+// Draws the N-byte sprite at I at (Vx, Vy), setting VF on collision
+func (cpu *Cpu) DRW(inst Instruction) error {
+	var sprite [0xF]uint8
+	for i := range inst.N {
+		val, err := cpu.Bus.Get8(cpu.I + uint16(i))
+		if err != nil {
+			return err
+		}
+		sprite[i] = val
+	}
+
+	x, y := cpu.V[inst.X], cpu.V[inst.Y]
+	collision := cpu.Display.DrawSprite(uint(x), uint(y), sprite[:inst.N])
+
+	cpu.V[0xF] = 0
+	if collision {
+		cpu.V[0xF] = 1
+	}
+
+	cpu.notifyDraw(x, y, inst.N, collision)
+	return nil
+}
+
+// Waits for a key by running again until one is held, then stores the lowest held key in Vx
+func (cpu *Cpu) LD_v_k(inst Instruction) error {
+	keys := cpu.Keypad.State()
+	if keys == 0 {
+		cpu.PC -= 2
+		cpu.notifyKeyWait(inst.X)
+		return nil
+	}
+
+	for key := range uint8(KeyCount) {
+		if keys&(1<<key) != 0 {
+			cpu.V[inst.X] = key
+			break
+		}
+	}
+	return nil
+}
+
+func (cpu *Cpu) LD_dt_v(inst Instruction) error {
+	cpu.setTimer(TimerDelay, cpu.V[inst.X])
+	return nil
+}
+
+func (cpu *Cpu) LD_st_v(inst Instruction) error {
+	cpu.setTimer(TimerSound, cpu.V[inst.X])
+	return nil
+}
+
 func (cpu *Cpu) GetPrettyCpuState() string {
 	var sb strings.Builder
 
@@ -218,12 +409,13 @@ func (cpu *Cpu) GetPrettyCpuState() string {
 	sb.WriteString("\n\n")
 
 	sb.WriteString("       ")
-	for i := range cpu.Stack {
+	stack := cpu.StackEntries()
+	for i := range stack {
 		sb.WriteString(fmt.Sprintf("%02X   ", i))
 	}
 	sb.WriteString("\n")
 	sb.WriteString("Stack: ")
-	for _, val := range cpu.Stack {
+	for _, val := range stack {
 		sb.WriteString(fmt.Sprintf("%04X ", val))
 	}
 	sb.WriteString("\n\n")
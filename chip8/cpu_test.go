@@ -10,12 +10,31 @@ import (
 	"github.com/tiendc/go-deepcopy"
 )
 
+func newPlatformCpu(t *testing.T, platform Platform, rom []byte) *Cpu {
+	t.Helper()
+
+	cpu, err := NewCpuWithROM(platform, rom)
+	if err != nil {
+		t.Fatalf("NewCpuWithROM() error = %v", err)
+	}
+	return cpu
+}
+
 func getRandomCpuState() *Cpu {
-	cpu := NewCpu()
+	return getRandomPlatformCpuState(PlatformChip8)
+}
+
+func getRandomPlatformCpuState(platform Platform) *Cpu {
+	cpu, err := NewCpuWithPlatform(platform)
+	if err != nil {
+		panic(err)
+	}
+
+	memorySize := len(cpu.Memory.Memory)
 
 	cpu.I = uint16(rand.Intn(0x10000))
-	cpu.PC = uint16(rand.Intn(MemorySize - 1)) // Last even memory address
-	cpu.SP = uint8(rand.Intn(StackSize))
+	cpu.PC = uint16(rand.Intn(memorySize - 1)) // Last even memory address
+	cpu.SP = uint8(rand.Intn(cpu.stackDepth()))
 	cpu.DT = uint8(rand.Intn(0x100))
 	cpu.ST = uint8(rand.Intn(0x100))
 
@@ -23,11 +42,11 @@ func getRandomCpuState() *Cpu {
 		cpu.V[i] = uint8(rand.Intn(0x100))
 	}
 
-	for i := range StackSize {
-		cpu.Stack[i] = uint16(rand.Intn(MemorySize - 1)) // Last even memory address
+	for i := range cpu.Stack {
+		cpu.Stack[i] = uint16(rand.Intn(memorySize - 1)) // Last even memory address
 	}
 
-	for i := range MemorySize {
+	for i := range memorySize {
 		cpu.Memory.Set8(uint16(i), uint8(rand.Intn(0x100))) // This also randomizes 'interpreter space', containing default sprites
 	}
 
@@ -73,24 +92,65 @@ func TestGetPrettyCpuState(t *testing.T) {
 func TestCLS(t *testing.T) {
 	cpu := NewCpu()
 
-	for y, row := range cpu.Display.framebuffer {
-		for x := range row {
-			cpu.Display.framebuffer[y][x] = rand.Intn(2) == 1
+	for y := range uint(height) {
+		for x := range uint(width) {
+			cpu.Display.setPixel(x, y, rand.Intn(2) == 1)
 		}
 	}
 
 	cpu.Memory.Set16(0x200, 0x00E0)
 	cpu.Tick()
 
-	for y, row := range cpu.Display.framebuffer {
-		for x := range row {
-			if cpu.Display.framebuffer[y][x] {
+	for y := range uint(height) {
+		for x := range uint(width) {
+			if cpu.Display.pixel(x, y) {
 				t.Errorf("CLS failed: screen not clear: %v", cpu.Display.PrintFrame())
 			}
 		}
 	}
 }
 
+func TestSYS(t *testing.T) {
+	t.Run("no handler", func(t *testing.T) {
+		inputCpuState := getRandomCpuState()
+
+		const opcode = 0x0123
+
+		inputCpuState.Memory.Set16(inputCpuState.PC, opcode)
+
+		wantCpuState := new(Cpu)
+		_ = deepcopy.Copy(&wantCpuState, &inputCpuState)
+
+		wantCpuState.PC = inputCpuState.PC + 2
+
+		err := opcodeTest{
+			inputCpuState: inputCpuState,
+			wantCpuState:  wantCpuState,
+			wantError:     false,
+		}.doOpcodeTest()
+
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		cpu := NewCpu()
+		cpu.Memory.Set16(0x200, 0x0123)
+
+		var gotAddr, gotPC uint16
+		cpu.Sys = func(cpu *Cpu, addr uint16) error {
+			gotAddr, gotPC = addr, cpu.PC
+			return nil
+		}
+
+		err := cpu.Tick()
+		if err != nil || gotAddr != 0x123 || gotPC != 0x202 {
+			t.Errorf("SYS handler got addr %04X, PC %04X, error = %v, want 0123 and 0202", gotAddr, gotPC, err)
+		}
+	})
+}
+
 func TestRET(t *testing.T) {
 	t.Run("random state", func(t *testing.T) {
 		const n_tests = 200
@@ -1420,3 +1480,226 @@ func TestADD_v1_v2(t *testing.T) {
 		}
 	})
 }
+
+func TestDRW(t *testing.T) {
+	tests := map[string]struct {
+		lit           [][2]uint
+		wantLit       [][2]uint
+		wantCollision bool
+	}{
+		"blank": {
+			wantLit: [][2]uint{{10, 5}, {17, 5}, {11, 6}},
+		},
+		"collision": {
+			lit:           [][2]uint{{10, 5}, {12, 6}},
+			wantLit:       [][2]uint{{17, 5}, {11, 6}, {12, 6}},
+			wantCollision: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			for _, p := range test.lit {
+				cpu.Display.Set(p[0], p[1], true)
+			}
+
+			cpu.V[0x1], cpu.V[0x2], cpu.V[0xF] = 10, 5, 0xAA
+			cpu.I = 0x300
+			cpu.Memory.Set16(0x300, 0x8140)
+			cpu.Memory.Set16(0x200, 0xD122) // DRW V1, V2, 2
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+
+			want := NewDisplay()
+			for _, p := range test.wantLit {
+				want.Set(p[0], p[1], true)
+			}
+			if cpu.Display.PrintFrame() != want.PrintFrame() {
+				t.Errorf("DRW drew %v, want %v", cpu.Display.PrintFrame(), want.PrintFrame())
+			}
+			if wantVF := map[bool]uint8{false: 0, true: 1}[test.wantCollision]; cpu.V[0xF] != wantVF {
+				t.Errorf("DRW set VF = %v, want %v", cpu.V[0xF], wantVF)
+			}
+		})
+	}
+}
+
+func TestLD_v_k(t *testing.T) {
+	tests := map[string]struct {
+		keys   uint16
+		wantV  uint8
+		wantPC uint16
+	}{
+		"no key":     {keys: 0x0000, wantV: 0xAA, wantPC: 0x200},
+		"one key":    {keys: 0x0100, wantV: 0x8, wantPC: 0x202},
+		"lowest key": {keys: 0x8024, wantV: 0x2, wantPC: 0x202},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			cpu.V[0x3] = 0xAA
+			cpu.Keypad.SetState(test.keys)
+			cpu.Memory.Set16(0x200, 0xF30A) // LD V3, K
+
+			err := cpu.Tick()
+			if err != nil {
+				t.Fatalf("Cpu.Tick() error = %v", err)
+			}
+
+			if cpu.V[0x3] != test.wantV || cpu.PC != test.wantPC {
+				t.Errorf("LD_v_k left V3 = %02X, PC = %04X, want %02X and %04X", cpu.V[0x3], cpu.PC, test.wantV, test.wantPC)
+			}
+		})
+	}
+}
+
+func TestLD_dt_v(t *testing.T) {
+	const n_tests = 200
+
+	for i := 0; i < n_tests; i++ {
+		r := uint16(rand.Intn(0x10))
+		opcode := 0xF015 | r<<8
+
+		inputCpuState := getRandomCpuState()
+		inputCpuState.Memory.Set16(inputCpuState.PC, opcode)
+
+		wantCpuState := new(Cpu)
+		_ = deepcopy.Copy(&wantCpuState, &inputCpuState)
+
+		wantCpuState.DT = inputCpuState.V[r]
+		wantCpuState.PC = inputCpuState.PC + 2
+
+		t.Run(fmt.Sprintf("LD_dt_v %04X", opcode), func(t *testing.T) {
+			err := opcodeTest{
+				inputCpuState: inputCpuState,
+				wantCpuState:  wantCpuState,
+				wantError:     false,
+			}.doOpcodeTest()
+
+			if err != nil {
+				t.Error(err.Error())
+			}
+		})
+	}
+}
+
+func TestLD_st_v(t *testing.T) {
+	const n_tests = 200
+
+	for i := 0; i < n_tests; i++ {
+		r := uint16(rand.Intn(0x10))
+		opcode := 0xF018 | r<<8
+
+		inputCpuState := getRandomCpuState()
+		inputCpuState.Memory.Set16(inputCpuState.PC, opcode)
+
+		wantCpuState := new(Cpu)
+		_ = deepcopy.Copy(&wantCpuState, &inputCpuState)
+
+		wantCpuState.ST = inputCpuState.V[r]
+		wantCpuState.PC = inputCpuState.PC + 2
+
+		t.Run(fmt.Sprintf("LD_st_v %04X", opcode), func(t *testing.T) {
+			err := opcodeTest{
+				inputCpuState: inputCpuState,
+				wantCpuState:  wantCpuState,
+				wantError:     false,
+			}.doOpcodeTest()
+
+			if err != nil {
+				t.Error(err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadROM(t *testing.T) {
+	tests := map[string]struct {
+		romSize int
+		wantErr bool
+	}{
+		"small":        {romSize: 0x10},
+		"maximum size": {romSize: MemorySize - 0x200},
+		"too large":    {romSize: MemorySize - 0x1FF, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+
+			rom := make([]byte, test.romSize)
+			for i := range rom {
+				rom[i] = uint8(rand.Intn(0x100))
+			}
+
+			err := cpu.LoadROM(rom)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Cpu.LoadROM() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(cpu.Memory.Memory[0x200:0x200+len(rom)], rom) {
+				t.Errorf("Cpu.LoadROM() did not copy ROM to 0x200")
+			}
+		})
+	}
+}
+
+// 0x200: ADD V0, 0x01 / LD V1, V0 / OR V2, V1 / AND V3, V2 / XOR V4, V3 / ADD V5, V4 /
+// SNE V0, 0x00 / ADD V6, 0x01 / SE V7, 0x01 / JP 0x200
+var benchmarkProgram = []uint16{0x7001, 0x8100, 0x8211, 0x8322, 0x8433, 0x8544, 0x4000, 0x7601, 0x3701, 0x1200}
+
+func newBenchmarkCpu() *Cpu {
+	cpu := NewCpu()
+	for i, opcode := range benchmarkProgram {
+		cpu.Memory.Set16(uint16(0x200+i*2), opcode)
+	}
+	return cpu
+}
+
+func TestTickAllocations(t *testing.T) {
+	cpu := newBenchmarkCpu()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		err := cpu.Tick()
+		if err != nil {
+			t.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Cpu.Tick() allocates %v times per instruction, want 0", allocs)
+	}
+}
+
+func BenchmarkTick(b *testing.B) {
+	cpu := newBenchmarkCpu()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		err := cpu.Tick()
+		if err != nil {
+			b.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	}
+
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "instructions/s")
+}
+
+func BenchmarkDecode(b *testing.B) {
+	var inst Instruction
+
+	for i := range b.N {
+		inst = Decode(uint16(i))
+	}
+
+	_ = inst
+}
@@ -0,0 +1,82 @@
+package chip8
+
+// Decoded instructions by address, so hot loops skip fetch and decode. Memory writes through
+// Set8/Set16 invalidate the instructions overlapping the written byte.
+type decodeCache struct {
+	valid []bool
+	insts []Instruction
+}
+
+func (cpu *Cpu) EnableDecodeCache() {
+	if cpu.decodeCache != nil {
+		return
+	}
+
+	cpu.decodeCache = &decodeCache{
+		valid: make([]bool, len(cpu.Memory.Memory)),
+		insts: make([]Instruction, len(cpu.Memory.Memory)),
+	}
+	cpu.Memory.addWriteObserver(cpu.decodeCache)
+}
+
+func (cpu *Cpu) DisableDecodeCache() {
+	if cpu.decodeCache == nil {
+		return
+	}
+
+	cpu.Memory.removeWriteObserver(cpu.decodeCache)
+	cpu.decodeCache = nil
+}
+
+// Drops every cached instruction - needed after writing cpu.Memory.Memory directly
+func (cpu *Cpu) FlushDecodeCache() {
+	if cpu.decodeCache != nil {
+		clear(cpu.decodeCache.valid)
+	}
+}
+
+func (cache *decodeCache) reload() {
+	clear(cache.valid)
+}
+
+func (cache *decodeCache) invalidate(addr uint16) {
+	// The byte at addr is the low half of the instruction at addr-1 and the high half of the one at addr
+	if addr > 0 {
+		cache.valid[addr-1] = false
+	}
+	if int(addr) < len(cache.valid) {
+		cache.valid[addr] = false
+	}
+}
+
+// The cache is bypassed when a custom Bus is attached, as writes through it may not reach Memory
+func (cpu *Cpu) fetch() (Instruction, error) {
+	cache := cpu.decodeCache
+	if cache != nil && cpu.Bus != Bus(cpu.Memory) {
+		cache = nil
+	}
+
+	if cache != nil && int(cpu.PC) < len(cache.valid) && cache.valid[cpu.PC] {
+		if cpu.Memory.hasExecuteHooks() {
+			err := cpu.Memory.runExecuteHooks(cpu.PC)
+			if err != nil {
+				return Instruction{}, err
+			}
+		}
+		return cache.insts[cpu.PC], nil
+	}
+
+	opcode, err := cpu.Bus.Fetch16(cpu.PC)
+	if err != nil {
+		return Instruction{}, err
+	}
+
+	inst := cpu.Decode(opcode)
+
+	if cache != nil {
+		cache.insts[cpu.PC] = inst
+		cache.valid[cpu.PC] = true
+	}
+
+	return inst, nil
+}
@@ -0,0 +1,147 @@
+package chip8
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestDecodeCache_SelfModifying(t *testing.T) {
+	tests := map[string]struct {
+		poke   func(mem *Memory)
+		wantV0 uint8
+	}{
+		"unmodified": {
+			poke:   func(mem *Memory) {},
+			wantV0: 0x04,
+		},
+		"Set16 over instruction": {
+			poke:   func(mem *Memory) { mem.Set16(0x200, 0x7010) },
+			wantV0: 0x22,
+		},
+		"Set8 high byte": {
+			poke:   func(mem *Memory) { mem.Set8(0x200, 0x71) },
+			wantV0: 0x02,
+		},
+		"Set8 low byte": {
+			poke:   func(mem *Memory) { mem.Set8(0x201, 0x20) },
+			wantV0: 0x42,
+		},
+		"Set16 straddling instructions": {
+			poke:   func(mem *Memory) { mem.Set16(0x1FF, 0x0071) },
+			wantV0: 0x02,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			cpu.EnableDecodeCache()
+
+			// 0x200: ADD V0, 0x01 / JP 0x200
+			cpu.Memory.Set16(0x200, 0x7001)
+			cpu.Memory.Set16(0x202, 0x1200)
+
+			for range 4 {
+				cpu.Tick()
+			}
+
+			test.poke(cpu.Memory)
+
+			for range 4 {
+				cpu.Tick()
+			}
+
+			if cpu.V[0x0] != test.wantV0 {
+				t.Errorf("V0 = %02X after self-modification, want %02X", cpu.V[0x0], test.wantV0)
+			}
+		})
+	}
+}
+
+func TestDecodeCache_LoadROM(t *testing.T) {
+	cpu := NewCpu()
+	cpu.EnableDecodeCache()
+
+	cpu.LoadROM([]byte{0x60, 0x01})
+	cpu.Tick()
+
+	cpu.PC = 0x200
+	cpu.LoadROM([]byte{0x60, 0x02})
+	cpu.Tick()
+
+	if cpu.V[0x0] != 0x02 {
+		t.Errorf("V0 = %02X after reloading ROM, want 02", cpu.V[0x0])
+	}
+}
+
+// Runs the same random program with and without the cache, poking random program bytes between ticks
+func TestDecodeCache_MatchesUncached(t *testing.T) {
+	const n_tests = 20
+	const n_ticks = 2000
+
+	// Ops that keep execution inside the program: register ops, skips and jumps back to 0x200
+	randomOpcode := func() uint16 {
+		x, y, nn := uint16(rand.Intn(0x10)), uint16(rand.Intn(0x10)), uint16(rand.Intn(0x100))
+		switch rand.Intn(6) {
+		case 0:
+			return 0x6000 | x<<8 | nn
+		case 1:
+			return 0x7000 | x<<8 | nn
+		case 2:
+			return 0x8000 | x<<8 | y<<4 | uint16(rand.Intn(5))
+		case 3:
+			return 0x3000 | x<<8 | nn
+		case 4:
+			return 0x4000 | x<<8 | nn
+		default:
+			return 0x1200 | uint16(rand.Intn(0x20))*2
+		}
+	}
+
+	for i := 0; i < n_tests; i++ {
+		plain := NewCpu()
+		for addr := uint16(0x200); addr < 0x240; addr += 2 {
+			plain.Memory.Set16(addr, randomOpcode())
+		}
+		plain.Memory.Set16(0x240, 0x1200)
+
+		cached := NewCpu()
+		cached.LoadState(plain.SaveState())
+		cached.EnableDecodeCache()
+
+		for tick := 0; tick < n_ticks; tick++ {
+			if rand.Intn(50) == 0 {
+				addr, opcode := uint16(0x200+rand.Intn(0x20)*2), randomOpcode()
+				plain.Memory.Set16(addr, opcode)
+				cached.Memory.Set16(addr, opcode)
+			}
+
+			plainErr := plain.Tick()
+			cachedErr := cached.Tick()
+			if (plainErr != nil) != (cachedErr != nil) {
+				t.Fatalf("tick %v: uncached error = %v, cached error = %v", tick, plainErr, cachedErr)
+			}
+
+			if !bytes.Equal(plain.SaveState(), cached.SaveState()) {
+				t.Fatalf("tick %v: cached state diverged: %v", tick, cached.GetPrettyCpuState())
+			}
+		}
+	}
+}
+
+func BenchmarkTickDecodeCache(b *testing.B) {
+	cpu := newBenchmarkCpu()
+	cpu.EnableDecodeCache()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		err := cpu.Tick()
+		if err != nil {
+			b.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	}
+
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "instructions/s")
+}
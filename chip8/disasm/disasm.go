@@ -0,0 +1,272 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/frasmataz/go-chip8/chip8"
+)
+
+type Syntax int
+
+const (
+	Classic Syntax = iota // Cowgod-style mnemonics, e.g. LD V1, 0x20
+	Octo                  // Octo assembly, e.g. v1 := 0x20
+)
+
+type Platform int
+
+const (
+	Chip8 Platform = iota
+	SuperChip
+	XOChip
+)
+
+type Options struct {
+	Syntax   Syntax
+	Platform Platform
+	Origin   uint16 // Address the first ROM byte is loaded at - 0x200 if zero
+
+	Extensions *chip8.Extensions // Optional - decode with these, as from Cpu.Extensions
+}
+
+type Line struct {
+	Addr  uint16
+	Bytes []byte
+	Label string // Set if this address is the target of a jump or call
+	Text  string
+	Data  bool // Bytes could not be decoded as an instruction
+}
+
+// Lowest platform each op is available on - ops not listed are base CHIP-8
+var opPlatforms = map[chip8.Op]Platform{
+	chip8.OpSCD:          SuperChip,
+	chip8.OpSCR:          SuperChip,
+	chip8.OpSCL:          SuperChip,
+	chip8.OpEXIT:         SuperChip,
+	chip8.OpLOW:          SuperChip,
+	chip8.OpHIGH:         SuperChip,
+	chip8.OpLD_hf_v:      SuperChip,
+	chip8.OpLD_r_v:       SuperChip,
+	chip8.OpLD_v_r:       SuperChip,
+	chip8.OpSCU:          XOChip,
+	chip8.OpLD_mem_range: XOChip,
+	chip8.OpLD_range_mem: XOChip,
+	chip8.OpLD_i_long:    XOChip,
+	chip8.OpPLANE:        XOChip,
+	chip8.OpAUDIO:        XOChip,
+	chip8.OpPITCH:        XOChip,
+}
+
+// Octo syntax for each op - %[1]x is X, %[2]x is Y, %[3]d is N, %02[4]X is NN, %[5]s is the address,
+// %02[6]X is the high byte of the address
+var octoFormats = map[chip8.Op]string{
+	chip8.OpSYS:          "0x%02[6]X 0x%02[4]X # machine code call",
+	chip8.OpCLS:          "clear",
+	chip8.OpRET:          "return",
+	chip8.OpJP:           "jump %[5]s",
+	chip8.OpCALL:         ":call %[5]s",
+	chip8.OpSE_v_byte:    "if v%[1]x != 0x%02[4]X then",
+	chip8.OpSNE_v_byte:   "if v%[1]x == 0x%02[4]X then",
+	chip8.OpSE_v1_v2:     "if v%[1]x != v%[2]x then",
+	chip8.OpLD_v_byte:    "v%[1]x := 0x%02[4]X",
+	chip8.OpADD_v_byte:   "v%[1]x += 0x%02[4]X",
+	chip8.OpLD_v1_v2:     "v%[1]x := v%[2]x",
+	chip8.OpOR_v1_v2:     "v%[1]x |= v%[2]x",
+	chip8.OpAND_v1_v2:    "v%[1]x &= v%[2]x",
+	chip8.OpXOR_v1_v2:    "v%[1]x ^= v%[2]x",
+	chip8.OpADD_v1_v2:    "v%[1]x += v%[2]x",
+	chip8.OpSUB_v1_v2:    "v%[1]x -= v%[2]x",
+	chip8.OpSHR_v1_v2:    "v%[1]x >>= v%[2]x",
+	chip8.OpSUBN_v1_v2:   "v%[1]x =- v%[2]x",
+	chip8.OpSHL_v1_v2:    "v%[1]x <<= v%[2]x",
+	chip8.OpSNE_v1_v2:    "if v%[1]x == v%[2]x then",
+	chip8.OpLD_i_addr:    "i := %[5]s",
+	chip8.OpJP_v0_addr:   "jump0 %[5]s",
+	chip8.OpRND_v_byte:   "v%[1]x := random 0x%02[4]X",
+	chip8.OpDRW:          "sprite v%[1]x v%[2]x %[3]d",
+	chip8.OpSKP:          "if v%[1]x -key then",
+	chip8.OpSKNP:         "if v%[1]x key then",
+	chip8.OpLD_v_dt:      "v%[1]x := delay",
+	chip8.OpLD_v_k:       "v%[1]x := key",
+	chip8.OpLD_dt_v:      "delay := v%[1]x",
+	chip8.OpLD_st_v:      "buzzer := v%[1]x",
+	chip8.OpADD_i_v:      "i += v%[1]x",
+	chip8.OpLD_f_v:       "i := hex v%[1]x",
+	chip8.OpLD_b_v:       "bcd v%[1]x",
+	chip8.OpLD_mem_v:     "save v%[1]x",
+	chip8.OpLD_v_mem:     "load v%[1]x",
+	chip8.OpSCD:          "scroll-down %[3]d",
+	chip8.OpSCR:          "scroll-right",
+	chip8.OpSCL:          "scroll-left",
+	chip8.OpEXIT:         "exit",
+	chip8.OpLOW:          "lores",
+	chip8.OpHIGH:         "hires",
+	chip8.OpLD_hf_v:      "i := bighex v%[1]x",
+	chip8.OpLD_r_v:       "saveflags v%[1]x",
+	chip8.OpLD_v_r:       "loadflags v%[1]x",
+	chip8.OpSCU:          "scroll-up %[3]d",
+	chip8.OpLD_mem_range: "save v%[1]x - v%[2]x",
+	chip8.OpLD_range_mem: "load v%[1]x - v%[2]x",
+	chip8.OpLD_i_long:    "i := long %[5]s",
+	chip8.OpPLANE:        "plane %[1]d",
+	chip8.OpAUDIO:        "audio",
+	chip8.OpPITCH:        "pitch := v%[1]x",
+}
+
+// Decodes opcode for the platform. SUPER-CHIP and XO-CHIP ops in the 0nnn range fall back to SYS on
+// platforms that lack them; anything else unavailable is invalid. Extensions apply on every platform.
+func decode(opcode uint16, opts Options) chip8.Instruction {
+	inst := opts.Extensions.Decode(opcode)
+
+	if opPlatforms[inst.Op] > opts.Platform {
+		if opcode&0xF000 == 0x0000 {
+			inst.Op = chip8.OpSYS
+		} else {
+			inst.Op = chip8.OpInvalid
+		}
+	}
+
+	return inst
+}
+
+func label(addr uint16) string {
+	return fmt.Sprintf("L%03X", addr)
+}
+
+func address(inst chip8.Instruction) string {
+	if inst.Length == 4 {
+		return fmt.Sprintf("0x%04X", inst.NNN)
+	}
+	return fmt.Sprintf("0x%03X", inst.NNN)
+}
+
+func format(inst chip8.Instruction, target string, syntax Syntax) string {
+	if syntax == Octo {
+		octo := octoFormats[inst.Op]
+		if inst.Ext != nil {
+			if inst.Ext.OctoFormat == "" {
+				return fmt.Sprintf("0x%02X 0x%02X # %v", inst.Opcode>>8, inst.NN, inst)
+			}
+			octo = inst.Ext.OctoFormat
+		}
+		if !strings.Contains(octo, "%") {
+			return octo
+		}
+		return fmt.Sprintf(octo, inst.X, inst.Y, inst.N, inst.NN, target, inst.NNN>>8)
+	}
+	return inst.Format(target)
+}
+
+// Disassembles a single 2-byte opcode without labels. Returns false if the opcode is not valid for the platform,
+// or is the first half of a 4-byte instruction.
+func Opcode(opcode uint16, opts Options) (string, bool) {
+	inst := decode(opcode, opts)
+	if inst.Op == chip8.OpInvalid || inst.Length != 2 {
+		return "", false
+	}
+
+	return format(inst, address(inst), opts.Syntax), true
+}
+
+// Linear sweep over rom, two bytes at a time. Jump and call targets inside the ROM are labelled.
+func Disassemble(rom []byte, opts Options) []Line {
+	origin := opts.Origin
+	if origin == 0 {
+		origin = 0x200
+	}
+
+	var lines []Line
+	var insts []chip8.Instruction
+	targets := make(map[uint16]bool)
+
+	for pc := 0; pc < len(rom); {
+		addr := origin + uint16(pc)
+
+		if pc+1 >= len(rom) {
+			lines = append(lines, Line{Addr: addr, Bytes: rom[pc:], Data: true})
+			insts = append(insts, chip8.Instruction{})
+			break
+		}
+
+		inst := decode(uint16(rom[pc])<<8|uint16(rom[pc+1]), opts)
+		if inst.Op == chip8.OpInvalid || pc+int(inst.Length) > len(rom) {
+			lines = append(lines, Line{Addr: addr, Bytes: rom[pc : pc+2], Data: true})
+			insts = append(insts, chip8.Instruction{})
+			pc += 2
+			continue
+		}
+
+		if inst.Length == 4 {
+			inst.NNN = uint16(rom[pc+2])<<8 | uint16(rom[pc+3])
+		}
+
+		if inst.Op.IsBranch() {
+			targets[inst.NNN] = true
+		}
+
+		lines = append(lines, Line{Addr: addr, Bytes: rom[pc : pc+int(inst.Length)]})
+		insts = append(insts, inst)
+		pc += int(inst.Length)
+	}
+
+	labelled := make(map[uint16]bool)
+	for i := range lines {
+		if targets[lines[i].Addr] {
+			lines[i].Label = label(lines[i].Addr)
+			labelled[lines[i].Addr] = true
+		}
+	}
+
+	for i, inst := range insts {
+		if lines[i].Data {
+			lines[i].Text = formatData(lines[i].Bytes, opts.Syntax)
+			continue
+		}
+
+		target := address(inst)
+		if inst.Op.IsBranch() && labelled[inst.NNN] {
+			target = label(inst.NNN)
+		}
+
+		lines[i].Text = format(inst, target, opts.Syntax)
+	}
+
+	return lines
+}
+
+func formatData(data []byte, syntax Syntax) string {
+	hex := make([]string, len(data))
+	for i, b := range data {
+		hex[i] = fmt.Sprintf("0x%02X", b)
+	}
+
+	if syntax == Octo {
+		return strings.Join(hex, " ")
+	}
+	return "DB " + strings.Join(hex, ", ")
+}
+
+// Full assembly listing of rom, with label declarations and addresses as comments
+func Listing(rom []byte, opts Options) string {
+	var sb strings.Builder
+
+	comment := ";"
+	if opts.Syntax == Octo {
+		comment = "#"
+	}
+
+	for _, line := range Disassemble(rom, opts) {
+		if line.Label != "" {
+			if opts.Syntax == Octo {
+				sb.WriteString(fmt.Sprintf(": %s\n", line.Label))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s:\n", line.Label))
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("\t%-24s %s %03X\n", line.Text, comment, line.Addr))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,189 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/frasmataz/go-chip8/chip8"
+)
+
+func TestOpcode(t *testing.T) {
+	tests := map[string]struct {
+		opcode   uint16
+		platform Platform
+		classic  string
+		octo     string
+		wantOk   bool
+	}{
+		"CLS":           {opcode: 0x00E0, classic: "CLS", octo: "clear", wantOk: true},
+		"RET":           {opcode: 0x00EE, classic: "RET", octo: "return", wantOk: true},
+		"SYS":           {opcode: 0x02A0, classic: "SYS 0x2A0", octo: "0x02 0xA0 # machine code call", wantOk: true},
+		"JP":            {opcode: 0x1234, classic: "JP 0x234", octo: "jump 0x234", wantOk: true},
+		"CALL":          {opcode: 0x2ABC, classic: "CALL 0xABC", octo: ":call 0xABC", wantOk: true},
+		"SE byte":       {opcode: 0x3A12, classic: "SE VA, 0x12", octo: "if va != 0x12 then", wantOk: true},
+		"SNE byte":      {opcode: 0x4B34, classic: "SNE VB, 0x34", octo: "if vb == 0x34 then", wantOk: true},
+		"SE regs":       {opcode: 0x5120, classic: "SE V1, V2", octo: "if v1 != v2 then", wantOk: true},
+		"LD byte":       {opcode: 0x6120, classic: "LD V1, 0x20", octo: "v1 := 0x20", wantOk: true},
+		"ADD byte":      {opcode: 0x7FFF, classic: "ADD VF, 0xFF", octo: "vf += 0xFF", wantOk: true},
+		"LD regs":       {opcode: 0x8120, classic: "LD V1, V2", octo: "v1 := v2", wantOk: true},
+		"SUBN":          {opcode: 0x8127, classic: "SUBN V1, V2", octo: "v1 =- v2", wantOk: true},
+		"SHL":           {opcode: 0x812E, classic: "SHL V1, V2", octo: "v1 <<= v2", wantOk: true},
+		"bad 8xy8":      {opcode: 0x8128},
+		"SNE regs":      {opcode: 0x9AB0, classic: "SNE VA, VB", octo: "if va == vb then", wantOk: true},
+		"LD I":          {opcode: 0xA123, classic: "LD I, 0x123", octo: "i := 0x123", wantOk: true},
+		"JP V0":         {opcode: 0xB300, classic: "JP V0, 0x300", octo: "jump0 0x300", wantOk: true},
+		"RND":           {opcode: 0xC30F, classic: "RND V3, 0x0F", octo: "v3 := random 0x0F", wantOk: true},
+		"DRW":           {opcode: 0xD125, classic: "DRW V1, V2, 5", octo: "sprite v1 v2 5", wantOk: true},
+		"SKP":           {opcode: 0xE59E, classic: "SKP V5", octo: "if v5 -key then", wantOk: true},
+		"SKNP":          {opcode: 0xE5A1, classic: "SKNP V5", octo: "if v5 key then", wantOk: true},
+		"LD DT":         {opcode: 0xF715, classic: "LD DT, V7", octo: "delay := v7", wantOk: true},
+		"LD F":          {opcode: 0xF729, classic: "LD F, V7", octo: "i := hex v7", wantOk: true},
+		"LD [I]":        {opcode: 0xF755, classic: "LD [I], V7", octo: "save v7", wantOk: true},
+		"SCHIP on base": {opcode: 0x00FF, classic: "SYS 0x0FF", octo: "0x00 0xFF # machine code call", wantOk: true},
+		"HIGH":          {opcode: 0x00FF, platform: SuperChip, classic: "HIGH", octo: "hires", wantOk: true},
+		"SCD":           {opcode: 0x00C4, platform: SuperChip, classic: "SCD 4", octo: "scroll-down 4", wantOk: true},
+		"LD HF":         {opcode: 0xF230, platform: SuperChip, classic: "LD HF, V2", octo: "i := bighex v2", wantOk: true},
+		"XO on SCHIP":   {opcode: 0x5122, platform: SuperChip},
+		"save range":    {opcode: 0x5122, platform: XOChip, classic: "LD [I], V1-V2", octo: "save v1 - v2", wantOk: true},
+		"plane":         {opcode: 0xF301, platform: XOChip, classic: "PLANE 3", octo: "plane 3", wantOk: true},
+		"long I":        {opcode: 0xF000, platform: XOChip},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			classic, ok := Opcode(test.opcode, Options{Syntax: Classic, Platform: test.platform})
+			if ok != test.wantOk {
+				t.Fatalf("Opcode(%04X) ok = %v, want %v", test.opcode, ok, test.wantOk)
+			}
+			if classic != test.classic {
+				t.Errorf("Opcode(%04X) classic = %q, want %q", test.opcode, classic, test.classic)
+			}
+
+			octo, _ := Opcode(test.opcode, Options{Syntax: Octo, Platform: test.platform})
+			if octo != test.octo {
+				t.Errorf("Opcode(%04X) octo = %q, want %q", test.opcode, octo, test.octo)
+			}
+		})
+	}
+}
+
+func TestOpcode_Extensions(t *testing.T) {
+	handler := func(cpu *chip8.Cpu, inst chip8.Instruction) error { return nil }
+
+	exts := new(chip8.Extensions)
+	exts.Add(chip8.Extension{Mask: 0xF00F, Value: 0x5001, Priority: chip8.PriorityFallback, Mnemonic: "SGT", Format: "V%[1]X, V%[2]X", Handler: handler})
+	exts.Add(chip8.Extension{Mask: 0xF0FF, Value: 0xF0F2, Mnemonic: "OUT", Format: "V%[1]X", OctoFormat: "out v%[1]x", Handler: handler})
+
+	tests := map[string]struct {
+		opcode  uint16
+		classic string
+		octo    string
+	}{
+		"fallback":    {opcode: 0x5121, classic: "SGT V1, V2", octo: "0x51 0x21 # SGT V1, V2"},
+		"octo format": {opcode: 0xF3F2, classic: "OUT V3", octo: "out v3"},
+		"built-in":    {opcode: 0x5120, classic: "SE V1, V2", octo: "if v1 != v2 then"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			classic, ok := Opcode(test.opcode, Options{Syntax: Classic, Extensions: exts})
+			if !ok || classic != test.classic {
+				t.Errorf("Opcode(%04X) classic = %q, %v, want %q", test.opcode, classic, ok, test.classic)
+			}
+
+			octo, _ := Opcode(test.opcode, Options{Syntax: Octo, Extensions: exts})
+			if octo != test.octo {
+				t.Errorf("Opcode(%04X) octo = %q, want %q", test.opcode, octo, test.octo)
+			}
+		})
+	}
+
+	if _, ok := Opcode(0x5121, Options{}); ok {
+		t.Errorf("Opcode(5121) ok without extensions, want false")
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	rom := []byte{
+		0x60, 0x05, // 200: LD V0, 0x05
+		0x22, 0x0A, // 202: CALL 20A
+		0x12, 0x02, // 204: JP 202
+		0xFF, 0xFF, // 206: data
+		0xF0, 0x00, // 208: long I, on XO-CHIP only
+		0x00, 0xEE, // 20A: RET
+		0x13, 0x00, // 20C: JP 300, outside the ROM
+		0xAB, // 20E: odd trailing byte
+	}
+
+	tests := map[string]struct {
+		opts Options
+		want []Line
+	}{
+		"classic": {
+			opts: Options{Syntax: Classic},
+			want: []Line{
+				{Addr: 0x200, Text: "LD V0, 0x05"},
+				{Addr: 0x202, Label: "L202", Text: "CALL L20A"},
+				{Addr: 0x204, Text: "JP L202"},
+				{Addr: 0x206, Text: "DB 0xFF, 0xFF", Data: true},
+				{Addr: 0x208, Text: "DB 0xF0, 0x00", Data: true},
+				{Addr: 0x20A, Label: "L20A", Text: "RET"},
+				{Addr: 0x20C, Text: "JP 0x300"},
+				{Addr: 0x20E, Text: "DB 0xAB", Data: true},
+			},
+		},
+		"octo xo-chip": {
+			opts: Options{Syntax: Octo, Platform: XOChip},
+			want: []Line{
+				{Addr: 0x200, Text: "v0 := 0x05"},
+				{Addr: 0x202, Label: "L202", Text: ":call 0x20A"},
+				{Addr: 0x204, Text: "jump L202"},
+				{Addr: 0x206, Text: "0xFF 0xFF", Data: true},
+				{Addr: 0x208, Text: "i := long 0x00EE"},
+				{Addr: 0x20C, Text: "jump 0x300"},
+				{Addr: 0x20E, Text: "0xAB", Data: true},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Disassemble(rom, test.opts)
+
+			if len(got) != len(test.want) {
+				t.Fatalf("Disassemble() returned %v lines, want %v: %+v", len(got), len(test.want), got)
+			}
+
+			for i, want := range test.want {
+				line := got[i]
+				if line.Addr != want.Addr || line.Label != want.Label || line.Text != want.Text || line.Data != want.Data {
+					t.Errorf("Disassemble() line %v = %+v, want %+v", i, line, want)
+				}
+			}
+		})
+	}
+}
+
+func TestListing(t *testing.T) {
+	rom := []byte{0x12, 0x02, 0x00, 0xE0, 0x12, 0x02}
+
+	classic := Listing(rom, Options{Syntax: Classic})
+	if !strings.Contains(classic, "L202:\n") || !strings.Contains(classic, "JP L202") {
+		t.Errorf("Listing() classic output missing label: %v", classic)
+	}
+
+	octo := Listing(rom, Options{Syntax: Octo})
+	if !strings.Contains(octo, ": L202\n") || !strings.Contains(octo, "# 202") {
+		t.Errorf("Listing() octo output missing label: %v", octo)
+	}
+}
+
+func TestOctoFormatsComplete(t *testing.T) {
+	for i := range 0x10000 {
+		inst := decode(uint16(i), Options{Platform: XOChip})
+		if inst.Op == chip8.OpInvalid {
+			continue
+		}
+
+		if _, ok := octoFormats[inst.Op]; !ok {
+			t.Errorf("no Octo format for %v (%04X)", inst.Op, i)
+		}
+	}
+}
@@ -5,9 +5,15 @@ import (
 	"strings"
 )
 
+// Standard display size, used unless a platform asks for another
 const width = 64
 const height = 32
 
+// Capacity of the packed framebuffer, enough for 128x64 hi-res modes
+const maxWidth = 128
+const maxHeight = 64
+const rowWords = maxWidth / 64
+
 var CharSprites = [16][5]uint8{
 	{ // 0
 		0xF0,
@@ -124,26 +130,264 @@ var CharSprites = [16][5]uint8{
 }
 
 type Display struct {
-	// Structure is [y][x] - makes row-by-row looping easier
-	framebuffer [height][width]bool
+	// One bit per pixel, rows of rowWords words with x = 0 in the most significant bit of the first
+	framebuffer [maxHeight][rowWords]uint64
+
+	width  uint
+	height uint
+
+	mem  *Memory // Set when pixels live in Memory rather than framebuffer
+	base uint16
+
+	colours *ColourZones // Set on colour platforms such as CHIP-8X
+
+	damage    Rect   // Pixels changed since the last Acknowledge
+	dirtyRows uint64 // Bit y set when row y has changed since the last Acknowledge
+}
+
+func NewDisplay() *Display {
+	return &Display{width: width, height: height}
+}
+
+// Display of w by h pixels, up to 128x64. w must be a whole number of bytes.
+func NewDisplaySize(w uint, h uint) (*Display, error) {
+	err := checkDisplaySize(w, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Display{width: w, height: h}, nil
+}
+
+func checkDisplaySize(w uint, h uint) error {
+	if w == 0 || w > maxWidth || w%8 != 0 || h == 0 || h > maxHeight {
+		return fmt.Errorf("invalid display size %vx%v, want up to %vx%v with whole bytes per row", w, h, maxWidth, maxHeight)
+	}
+	return nil
+}
+
+// Bytes taken by a memory-mapped framebuffer of w by h pixels, one bit per pixel, rows left to right from the
+// most significant bit
+func mappedDisplaySize(w uint, h uint) int {
+	return int(w * h / 8)
+}
+
+// Display whose pixels are the mapped bytes of mem at base, as on the VIP. Memory writes show up as pixels,
+// and drawing writes Memory directly, bypassing hooks.
+func NewMappedDisplay(mem *Memory, base uint16) (*Display, error) {
+	return NewMappedDisplaySize(mem, base, width, height)
+}
+
+func NewMappedDisplaySize(mem *Memory, base uint16, w uint, h uint) (*Display, error) {
+	err := checkDisplaySize(w, h)
+	if err != nil {
+		return nil, err
+	}
+	if int(base)+mappedDisplaySize(w, h) > len(mem.Memory) {
+		return nil, fmt.Errorf("display at %04X does not fit in %v bytes of memory", base, len(mem.Memory))
+	}
+
+	display := &Display{width: w, height: h, mem: mem, base: base}
+	mem.addWriteObserver(display) // Picks up programs writing display memory directly, as well as drawing
+
+	return display, nil
+}
+
+func (display *Display) Width() uint {
+	return display.width
+}
+
+func (display *Display) Height() uint {
+	return display.height
+}
+
+func (display *Display) pixel(x uint, y uint) bool {
+	if display.mem != nil {
+		return display.mem.Memory[display.pixelAddr(x, y)]&(0x80>>(x%8)) != 0
+	}
+	return display.framebuffer[y][x/64]&pixelBit(x) != 0
+}
+
+// Bit for column x within its framebuffer word
+func pixelBit(x uint) uint64 {
+	return 1 << (63 - x%64)
+}
+
+func (display *Display) setPixel(x uint, y uint, val bool) {
+	if display.pixel(x, y) == val {
+		return
+	}
+
+	if display.mem == nil {
+		display.framebuffer[y][x/64] ^= pixelBit(x)
+		display.markDirty(x, y, 1)
+		return
+	}
+
+	addr := display.pixelAddr(x, y)
+	b := display.mem.Memory[addr] &^ (0x80 >> (x % 8))
+	if val {
+		b |= 0x80 >> (x % 8)
+	}
+	display.mem.poke(addr, b)
+}
+
+func (display *Display) pixelAddr(x uint, y uint) uint16 {
+	return display.base + uint16(y*display.width/8+x/8)
+}
+
+// Mapped displays are kept up to date by their Memory, including writes made by drawing
+func (display *Display) invalidate(addr uint16) {
+	if addr < display.base || int(addr-display.base) >= mappedDisplaySize(display.width, display.height) {
+		return
+	}
+
+	offset := uint(addr - display.base)
+	display.markDirty(offset%(display.width/8)*8, offset/(display.width/8), 8)
+}
+
+func (display *Display) reload() {
+	display.markAllDirty()
+}
+
+func (display *Display) markDirty(x uint, y uint, w uint) {
+	display.damage = display.damage.Union(Rect{X: x, Y: y, W: w, H: 1})
+	display.dirtyRows |= 1 << y
+}
+
+func (display *Display) markAllDirty() {
+	for y := range display.height {
+		display.markDirty(0, y, display.width)
+	}
+}
+
+// Smallest Rect covering every pixel changed since the last Acknowledge - empty if none have
+func (display *Display) Damage() Rect {
+	return display.damage
+}
+
+// Rows changed since the last Acknowledge, top to bottom
+func (display *Display) DirtyRows() []uint {
+	var rows []uint
+	for y := range display.height {
+		if display.dirtyRows&(1<<y) != 0 {
+			rows = append(rows, y)
+		}
+	}
+	return rows
+}
+
+// Marks the display as drawn, so changes are tracked afresh from here
+func (display *Display) Acknowledge() {
+	display.damage = Rect{}
+	display.dirtyRows = 0
+}
+
+func (display *Display) Clear() {
+	if display.mem == nil {
+		for y := range display.height {
+			if display.framebuffer[y] != [rowWords]uint64{} {
+				display.framebuffer[y] = [rowWords]uint64{}
+				display.markDirty(0, y, display.width)
+			}
+		}
+		return
+	}
+
+	for i := range mappedDisplaySize(display.width, display.height) {
+		addr := display.base + uint16(i)
+		if display.mem.Memory[addr] != 0x00 {
+			display.mem.poke(addr, 0x00)
+		}
+	}
+}
+
+// XORs an 8-pixel-wide sprite onto the display, one byte per row, returning whether any lit pixel was
+// turned off. The sprite's origin wraps around the display, and the sprite is clipped at its edges.
+func (display *Display) DrawSprite(x uint, y uint, sprite []uint8) bool {
+	x, y = x%display.width, y%display.height
+	collision := false
+
+	for i, b := range sprite {
+		row := y + uint(i)
+		if row >= display.height {
+			break
+		}
+		if b == 0 {
+			continue
+		}
+
+		if display.mem == nil {
+			collision = display.drawRow(x, row, b) || collision
+		} else {
+			collision = display.drawMappedRow(x, row, b) || collision
+		}
+	}
+
+	return collision
+}
+
+// Sprite byte b at column x spans at most two words - one shift, AND and XOR for each
+func (display *Display) drawRow(x uint, y uint, b uint8) bool {
+	words := &display.framebuffer[y]
+	w, offset := x/64, x%64
+
+	mask := uint64(b) << 56 >> offset & display.visible(w)
+	collision := words[w]&mask != 0
+	words[w] ^= mask
+
+	if offset > 56 && (w+1)*64 < display.width {
+		mask := uint64(b) << (120 - offset) & display.visible(w+1)
+		collision = collision || words[w+1]&mask != 0
+		words[w+1] ^= mask
+	}
+
+	display.markDirty(x, y, min(8, display.width-x))
+	return collision
+}
+
+// Bits of row word w that are on the display - all of them unless the width ends inside it
+func (display *Display) visible(w uint) uint64 {
+	if display.width >= (w+1)*64 {
+		return ^uint64(0)
+	}
+	return ^uint64(0) << ((w+1)*64 - display.width)
+}
+
+// As drawRow, for a display in Memory - a byte-aligned row of 8 pixels per address
+func (display *Display) drawMappedRow(x uint, y uint, b uint8) bool {
+	addr := display.pixelAddr(x, y)
+	offset := x % 8
+
+	first := b >> offset
+	collision := display.mem.Memory[addr]&first != 0
+	display.mem.poke(addr, display.mem.Memory[addr]^first)
+
+	if offset > 0 && x/8+1 < display.width/8 {
+		second := b << (8 - offset)
+		collision = collision || display.mem.Memory[addr+1]&second != 0
+		display.mem.poke(addr+1, display.mem.Memory[addr+1]^second)
+	}
+
+	return collision
 }
 
 func (display *Display) Set(x uint, y uint, val bool) error {
-	if x >= width || y >= height {
+	if x >= display.width || y >= display.height {
 		return fmt.Errorf("pixel coordinate out of range: x: %v, y: %v", x, y)
 	}
 
-	display.framebuffer[y][x] = val
+	display.setPixel(x, y, val)
 
 	return nil
 }
 
 func (display *Display) Get(x uint, y uint) (bool, error) {
-	if x >= width || y >= height {
+	if x >= display.width || y >= display.height {
 		return false, fmt.Errorf("pixel coordinate out of range: x: %v, y: %v", x, y)
 	}
 
-	return display.framebuffer[y][x], nil
+	return display.pixel(x, y), nil
 }
 
 func (display *Display) PrintFrame() string {
@@ -151,21 +395,24 @@ func (display *Display) PrintFrame() string {
 
 	sb.WriteRune('\n')
 
-	for y, row := range display.framebuffer {
-		for x := range row {
-			if display.framebuffer[y][x] {
+	for y := range display.height {
+		for x := range display.width {
+			if display.colours != nil {
+				// 24-bit ANSI colour, so both lit and unlit pixels are solid blocks
+				rgba := display.colourAt(x, y).RGBA()
+				sb.WriteString(fmt.Sprintf("\x1b[38;2;%d;%d;%dm██", rgba.R, rgba.G, rgba.B))
+			} else if display.pixel(x, y) {
 				sb.WriteString("██")
 			} else {
 				sb.WriteString("░░")
 			}
 		}
 
+		if display.colours != nil {
+			sb.WriteString("\x1b[0m")
+		}
 		sb.WriteRune('\n')
 	}
 
 	return sb.String()
 }
-
-func NewDisplay() *Display {
-	return new(Display)
-}
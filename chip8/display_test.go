@@ -1,6 +1,8 @@
 package chip8
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -48,7 +50,7 @@ func TestSet(t *testing.T) {
 			display := NewDisplay()
 
 			for _, poke := range test.displayPokes {
-				display.framebuffer[poke.y][poke.x] = poke.val
+				display.setPixel(poke.x, poke.y, poke.val)
 			}
 
 			err := display.Set(test.setx, test.sety, test.val)
@@ -66,7 +68,7 @@ func TestSet(t *testing.T) {
 				return
 			}
 
-			got := display.framebuffer[test.sety][test.setx]
+			got := display.pixel(test.setx, test.sety)
 
 			if got != test.val {
 				t.Errorf("Display.Set() wrote a %v, want %v", got, test.val)
@@ -119,7 +121,7 @@ func TestGet(t *testing.T) {
 			display := NewDisplay()
 
 			for _, poke := range test.displayPokes {
-				display.framebuffer[poke.y][poke.x] = poke.val
+				display.setPixel(poke.x, poke.y, poke.val)
 			}
 
 			got, err := display.Get(test.getx, test.gety)
@@ -230,7 +232,7 @@ func TestPrintFrame(t *testing.T) {
 			display := NewDisplay()
 
 			for _, poke := range test.displayPokes {
-				display.framebuffer[poke.y][poke.x] = poke.val
+				display.setPixel(poke.x, poke.y, poke.val)
 			}
 
 			output := display.PrintFrame()
@@ -238,3 +240,384 @@ func TestPrintFrame(t *testing.T) {
 		})
 	}
 }
+
+func TestMappedDisplay(t *testing.T) {
+	tests := map[string]struct {
+		x, y     uint
+		wantAddr uint16
+		wantBit  uint8
+	}{
+		"top left":     {x: 0, y: 0, wantAddr: 0xF00, wantBit: 0x80},
+		"top right":    {x: 63, y: 0, wantAddr: 0xF07, wantBit: 0x01},
+		"second row":   {x: 9, y: 1, wantAddr: 0xF09, wantBit: 0x40},
+		"bottom right": {x: 63, y: 31, wantAddr: 0xFFF, wantBit: 0x01},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mem := NewMemory()
+			display, err := NewMappedDisplay(mem, 0xF00)
+			if err != nil {
+				t.Fatalf("NewMappedDisplay() error = %v", err)
+			}
+
+			display.Set(test.x, test.y, true)
+			if mem.Memory[test.wantAddr] != test.wantBit {
+				t.Errorf("Display.Set() wrote %02X to %04X, want %02X", mem.Memory[test.wantAddr], test.wantAddr, test.wantBit)
+			}
+
+			mem.Set8(test.wantAddr, 0x00)
+			if got, _ := display.Get(test.x, test.y); got {
+				t.Errorf("Display.Get() = true after clearing %04X in memory", test.wantAddr)
+			}
+
+			mem.Set8(test.wantAddr, test.wantBit)
+			if got, _ := display.Get(test.x, test.y); !got {
+				t.Errorf("Display.Get() = false after setting %04X in memory", test.wantAddr)
+			}
+		})
+	}
+}
+
+func TestMappedDisplay_OutOfRange(t *testing.T) {
+	_, err := NewMappedDisplay(NewMemory(), 0xF01)
+	if err == nil {
+		t.Errorf("NewMappedDisplay() did not throw error as wanted")
+	}
+}
+
+func TestNewDisplaySize(t *testing.T) {
+	tests := map[string]struct {
+		w, h    uint
+		wantErr bool
+	}{
+		"standard":      {w: 64, h: 32},
+		"hi-res":        {w: 128, h: 64},
+		"too wide":      {w: 136, h: 64, wantErr: true},
+		"too tall":      {w: 128, h: 65, wantErr: true},
+		"partial bytes": {w: 60, h: 32, wantErr: true},
+		"empty":         {w: 0, h: 0, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			display, err := NewDisplaySize(test.w, test.h)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewDisplaySize() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if display.Width() != test.w || display.Height() != test.h {
+				t.Errorf("display is %vx%v, want %vx%v", display.Width(), display.Height(), test.w, test.h)
+			}
+			if _, err := display.Get(test.w, 0); err == nil {
+				t.Errorf("Display.Get(%v, 0) error = %v, wantErr true", test.w, err)
+			}
+			if err := display.Set(test.w-1, test.h-1, true); err != nil {
+				t.Errorf("Display.Set(%v, %v) error = %v", test.w-1, test.h-1, err)
+			}
+		})
+	}
+}
+
+func TestMappedDisplay_Clear(t *testing.T) {
+	cpu, err := NewCpuWithPlatform(PlatformVIP)
+	if err != nil {
+		t.Fatalf("NewCpuWithPlatform() error = %v", err)
+	}
+
+	for addr := 0xEFF; addr < 0x1000; addr++ {
+		cpu.Memory.Memory[addr] = 0xFF
+	}
+	cpu.LoadROM([]byte{0x00, 0xE0}) // CLS
+	cpu.Tick()
+
+	for addr := 0xF00; addr < 0x1000; addr++ {
+		if cpu.Memory.Memory[addr] != 0x00 {
+			t.Fatalf("CLS left %02X at %04X", cpu.Memory.Memory[addr], addr)
+		}
+	}
+	if cpu.Memory.Memory[0xEFF] != 0xFF {
+		t.Errorf("CLS cleared memory below the display")
+	}
+}
+
+func TestDisplay_Damage(t *testing.T) {
+	tests := map[string]struct {
+		setup      func(display *Display)
+		change     func(display *Display)
+		wantDamage Rect
+		wantRows   []uint
+	}{
+		"nothing": {
+			change: func(display *Display) {},
+		},
+		"set": {
+			change: func(display *Display) {
+				display.Set(3, 4, true)
+				display.Set(10, 6, true)
+			},
+			wantDamage: Rect{X: 3, Y: 4, W: 8, H: 3},
+			wantRows:   []uint{4, 6},
+		},
+		"set unchanged": {
+			setup:  func(display *Display) { display.Set(3, 4, true) },
+			change: func(display *Display) { display.Set(3, 4, true) },
+		},
+		"clear": {
+			setup: func(display *Display) {
+				display.Set(0, 1, true)
+				display.Set(5, 20, true)
+			},
+			change:     func(display *Display) { display.Clear() },
+			wantDamage: Rect{X: 0, Y: 1, W: width, H: 20},
+			wantRows:   []uint{1, 20},
+		},
+		"clear blank": {
+			change: func(display *Display) { display.Clear() },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			display := NewDisplay()
+			if test.setup != nil {
+				test.setup(display)
+			}
+			display.Acknowledge()
+
+			test.change(display)
+
+			if display.Damage() != test.wantDamage || !reflect.DeepEqual(display.DirtyRows(), test.wantRows) {
+				t.Errorf("Display.Damage() = %+v, DirtyRows() = %v, want %+v and %v",
+					display.Damage(), display.DirtyRows(), test.wantDamage, test.wantRows)
+			}
+
+			display.Acknowledge()
+			if !display.Damage().Empty() || display.DirtyRows() != nil {
+				t.Errorf("Display.Acknowledge() left Damage() = %+v, DirtyRows() = %v", display.Damage(), display.DirtyRows())
+			}
+		})
+	}
+}
+
+func TestMappedDisplay_Damage(t *testing.T) {
+	cpu, err := NewCpuWithPlatform(PlatformVIP)
+	if err != nil {
+		t.Fatalf("NewCpuWithPlatform() error = %v", err)
+	}
+	display := cpu.Display
+
+	cpu.Memory.Set8(PlatformVIP.DisplayBase-1, 0xFF)
+	if !display.Damage().Empty() {
+		t.Errorf("write below display memory damaged %+v", display.Damage())
+	}
+
+	cpu.Memory.Set8(PlatformVIP.DisplayBase+8*2+1, 0xFF) // Row 2, pixels 8 - 15
+	display.Set(0, 5, true)
+	if want := (Rect{X: 0, Y: 2, W: 16, H: 4}); display.Damage() != want || !reflect.DeepEqual(display.DirtyRows(), []uint{2, 5}) {
+		t.Errorf("Display.Damage() = %+v, DirtyRows() = %v, want %+v and [2 5]", display.Damage(), display.DirtyRows(), want)
+	}
+
+	display.Acknowledge()
+	state := cpu.SaveState()
+	cpu.LoadState(state)
+	if want := (Rect{W: width, H: height}); display.Damage() != want {
+		t.Errorf("Display.Damage() after LoadState = %+v, want %+v", display.Damage(), want)
+	}
+}
+
+// Pixel-at-a-time reference for DrawSprite, as drawing worked before the framebuffer was packed
+func drawSpritePixels(display *Display, x uint, y uint, sprite []uint8) bool {
+	x, y = x%width, y%height
+	collision := false
+
+	for row, b := range sprite {
+		for bit := range uint(8) {
+			px, py := x+bit, y+uint(row)
+			if px >= width || py >= height || b&(0x80>>bit) == 0 {
+				continue
+			}
+
+			lit, _ := display.Get(px, py)
+			collision = collision || lit
+			display.Set(px, py, !lit)
+		}
+	}
+
+	return collision
+}
+
+func TestDrawSprite(t *testing.T) {
+	tests := map[string]struct {
+		x, y          uint
+		sprite        []uint8
+		lit           [][2]uint
+		wantLit       [][2]uint
+		wantCollision bool
+	}{
+		"aligned": {
+			x: 8, y: 2, sprite: []uint8{0x81},
+			wantLit: [][2]uint{{8, 2}, {15, 2}},
+		},
+		"unaligned": {
+			x: 13, y: 0, sprite: []uint8{0xC0, 0x01},
+			wantLit: [][2]uint{{13, 0}, {14, 0}, {20, 1}},
+		},
+		"collision": {
+			x: 0, y: 0, sprite: []uint8{0xC0},
+			lit:           [][2]uint{{1, 0}, {5, 0}},
+			wantLit:       [][2]uint{{0, 0}, {5, 0}},
+			wantCollision: true,
+		},
+		"no collision": {
+			x: 0, y: 0, sprite: []uint8{0x0F},
+			lit:     [][2]uint{{1, 0}},
+			wantLit: [][2]uint{{1, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}},
+		},
+		"origin wraps": {
+			x: width + 2, y: height + 1, sprite: []uint8{0x80},
+			wantLit: [][2]uint{{2, 1}},
+		},
+		"clipped right": {
+			x: width - 2, y: 0, sprite: []uint8{0xFF},
+			wantLit: [][2]uint{{width - 2, 0}, {width - 1, 0}},
+		},
+		"clipped bottom": {
+			x: 0, y: height - 1, sprite: []uint8{0x80, 0x80},
+			wantLit: [][2]uint{{0, height - 1}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			display := NewDisplay()
+			for _, p := range test.lit {
+				display.Set(p[0], p[1], true)
+			}
+
+			collision := display.DrawSprite(test.x, test.y, test.sprite)
+			if collision != test.wantCollision {
+				t.Errorf("Display.DrawSprite() = %v, want %v", collision, test.wantCollision)
+			}
+
+			want := NewDisplay()
+			for _, p := range test.wantLit {
+				want.Set(p[0], p[1], true)
+			}
+			if display.PrintFrame() != want.PrintFrame() {
+				t.Errorf("Display.DrawSprite() drew %v, want %v", display.PrintFrame(), want.PrintFrame())
+			}
+		})
+	}
+}
+
+func TestDrawSprite_MatchesPixels(t *testing.T) {
+	newDisplays := map[string]func() *Display{
+		"packed": NewDisplay,
+		"mapped": func() *Display {
+			display, _ := NewMappedDisplay(NewMemory(), 0xF00)
+			return display
+		},
+	}
+
+	for name, newDisplay := range newDisplays {
+		t.Run(name, func(t *testing.T) {
+			display, reference := newDisplay(), NewDisplay()
+
+			for range 1000 {
+				x, y := uint(rand.Intn(0x100)), uint(rand.Intn(0x100))
+				sprite := make([]uint8, rand.Intn(16))
+				for i := range sprite {
+					sprite[i] = uint8(rand.Intn(0x100))
+				}
+
+				got := display.DrawSprite(x, y, sprite)
+				want := drawSpritePixels(reference, x, y, sprite)
+				if got != want || display.PrintFrame() != reference.PrintFrame() {
+					t.Fatalf("Display.DrawSprite(%v, %v, %X) = %v, want %v, drew %v, want %v",
+						x, y, sprite, got, want, display.PrintFrame(), reference.PrintFrame())
+				}
+			}
+		})
+	}
+}
+
+// Pixels past a width that ends inside a row word must not be drawn, or they collide with later sprites
+func TestDrawSprite_RightEdge(t *testing.T) {
+	tests := map[string]struct {
+		x             uint
+		sprite        uint8
+		wantLit       []uint
+		wantCollision bool
+	}{
+		"hidden only":   {x: 92, sprite: 0x0F},
+		"edge":          {x: 92, sprite: 0xFF, wantLit: []uint{92, 93, 94, 95}, wantCollision: true},
+		"across a word": {x: 60, sprite: 0xFF, wantLit: []uint{60, 61, 62, 63, 64, 65, 66, 67}, wantCollision: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			display, err := NewDisplaySize(96, 64)
+			if err != nil {
+				t.Fatalf("NewDisplaySize() error = %v", err)
+			}
+
+			display.DrawSprite(test.x, 0, []uint8{test.sprite})
+			collision := display.DrawSprite(test.x, 0, []uint8{test.sprite})
+			if collision != test.wantCollision {
+				t.Errorf("second Display.DrawSprite() = %v, want %v", collision, test.wantCollision)
+			}
+
+			display.DrawSprite(test.x, 0, []uint8{test.sprite})
+			want, _ := NewDisplaySize(96, 64)
+			for _, x := range test.wantLit {
+				want.Set(x, 0, true)
+			}
+			if display.PrintFrame() != want.PrintFrame() {
+				t.Errorf("Display.DrawSprite() drew %v, want %v", display.PrintFrame(), want.PrintFrame())
+			}
+			if display.framebuffer[0] != want.framebuffer[0] {
+				t.Errorf("row words = %X, want %X", display.framebuffer[0], want.framebuffer[0])
+			}
+		})
+	}
+}
+
+func TestDrawSprite_Damage(t *testing.T) {
+	display := NewDisplay()
+
+	display.DrawSprite(width-4, 3, []uint8{0xFF, 0x00, 0x18})
+
+	if want := (Rect{X: width - 4, Y: 3, W: 4, H: 3}); display.Damage() != want || !reflect.DeepEqual(display.DirtyRows(), []uint{3, 5}) {
+		t.Errorf("Display.Damage() = %+v, DirtyRows() = %v, want %+v and [3 5]", display.Damage(), display.DirtyRows(), want)
+	}
+}
+
+var benchmarkSprite = []uint8{0x3C, 0x42, 0xA5, 0x81, 0xA5, 0x99, 0x42, 0x3C}
+
+func BenchmarkDrawSprite(b *testing.B) {
+	display := NewDisplay()
+
+	for i := range b.N {
+		display.DrawSprite(uint(i), uint(i/width), benchmarkSprite)
+	}
+}
+
+func BenchmarkDrawSprite_Mapped(b *testing.B) {
+	display, _ := NewMappedDisplay(NewMemory(), 0xF00)
+
+	for i := range b.N {
+		display.DrawSprite(uint(i), uint(i/width), benchmarkSprite)
+	}
+}
+
+func BenchmarkDrawSprite_Pixels(b *testing.B) {
+	display := NewDisplay()
+
+	for i := range b.N {
+		drawSpritePixels(display, uint(i), uint(i/width), benchmarkSprite)
+	}
+}
@@ -0,0 +1,172 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExtensionPriority int
+
+const (
+	PriorityOverride ExtensionPriority = iota // Takes matching opcodes from the built-in ops
+	PriorityFallback                          // Only takes opcodes that no built-in op decodes
+)
+
+// Extension handles the opcodes matching Value under Mask, for variant and homebrew instruction sets.
+// Extended instructions decode to OpExtension, with Instruction.Ext pointing at the Extension.
+type Extension struct {
+	Name       string // Instruction set the extension belongs to, e.g. "CHIP-8E"
+	Mask       uint16
+	Value      uint16
+	Priority   ExtensionPriority
+	Mnemonic   string
+	Format     string // Classic syntax operands, with the same verbs as the built-in ops - %[1]X is X, %[2]X is Y, %[3]d is N, %02[4]X is NN, %[5]s is the address
+	OctoFormat string // Optional - Octo syntax, with the same verbs as Format. Without it, Octo listings show the opcode bytes.
+	Handler    func(cpu *Cpu, inst Instruction) error
+}
+
+type ExtensionID int
+
+// Extensions is an ordered set of Extensions. Overrides are tried before the built-in ops and fallbacks
+// after them; within each, the most recently added Extension wins.
+type Extensions struct {
+	nextID  ExtensionID
+	entries []extensionEntry
+}
+
+type extensionEntry struct {
+	id  ExtensionID
+	ext *Extension
+}
+
+func (ext *Extension) validate() error {
+	if ext.Handler == nil {
+		return fmt.Errorf("extension %v has no handler", ext.Mnemonic)
+	}
+	if ext.Mnemonic == "" {
+		return fmt.Errorf("extension for %04X/%04X has no mnemonic", ext.Value, ext.Mask)
+	}
+	if ext.Value&^ext.Mask != 0 {
+		return fmt.Errorf("extension %v value %04X has bits outside mask %04X", ext.Mnemonic, ext.Value, ext.Mask)
+	}
+	if ext.Priority != PriorityOverride && ext.Priority != PriorityFallback {
+		return fmt.Errorf("extension %v has invalid priority %v", ext.Mnemonic, ext.Priority)
+	}
+
+	// Formats get every operand, so a verb without an index leaves the rest rendered as %!(EXTRA ...)
+	if ext.Format != "" && strings.Contains(fmt.Sprintf(ext.Format, uint8(0), uint8(0), uint8(0), uint8(0), "0x000"), "%!") {
+		return fmt.Errorf("extension %v has invalid format %q", ext.Mnemonic, ext.Format)
+	}
+	if strings.Contains(ext.OctoFormat, "%") && strings.Contains(fmt.Sprintf(ext.OctoFormat, uint8(0), uint8(0), uint8(0), uint8(0), "0x000", uint16(0)), "%!") {
+		return fmt.Errorf("extension %v has invalid Octo format %q", ext.Mnemonic, ext.OctoFormat)
+	}
+	return nil
+}
+
+func (exts *Extensions) Add(ext Extension) (ExtensionID, error) {
+	err := ext.validate()
+	if err != nil {
+		return 0, err
+	}
+
+	exts.nextID++
+	exts.entries = append(exts.entries, extensionEntry{exts.nextID, &ext})
+	return exts.nextID, nil
+}
+
+func (exts *Extensions) Remove(id ExtensionID) {
+	for i, entry := range exts.entries {
+		if entry.id == id {
+			exts.entries = append(exts.entries[:i], exts.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (exts *Extensions) Len() int {
+	if exts == nil {
+		return 0
+	}
+	return len(exts.entries)
+}
+
+// Decodes opcode, letting the extensions take it from the built-in ops according to their priority.
+// A nil Extensions decodes built-in ops only.
+func (exts *Extensions) Decode(opcode uint16) Instruction {
+	inst := Decode(opcode)
+	if exts == nil {
+		return inst
+	}
+
+	if ext := exts.match(opcode, PriorityOverride); ext != nil {
+		return ext.instruction(opcode)
+	}
+	if inst.Op == OpInvalid {
+		if ext := exts.match(opcode, PriorityFallback); ext != nil {
+			return ext.instruction(opcode)
+		}
+	}
+
+	return inst
+}
+
+func (exts *Extensions) match(opcode uint16, priority ExtensionPriority) *Extension {
+	for i := len(exts.entries) - 1; i >= 0; i-- {
+		ext := exts.entries[i].ext
+		if ext.Priority == priority && opcode&ext.Mask == ext.Value {
+			return ext
+		}
+	}
+	return nil
+}
+
+// Extended instructions are always 2 bytes, with the operand fields filled as for built-in ops
+func (ext *Extension) instruction(opcode uint16) Instruction {
+	inst := Decode(opcode)
+	inst.Op = OpExtension
+	inst.Ext = ext
+	inst.NNN = opcode & 0x0FFF
+	inst.Length = 2
+	return inst
+}
+
+// Adds ext to the Cpu's instruction set. Decoded instructions and compiled blocks are flushed, so it takes
+// effect immediately.
+func (cpu *Cpu) AddExtension(ext Extension) (ExtensionID, error) {
+	if cpu.extensions == nil {
+		cpu.extensions = new(Extensions)
+	}
+
+	id, err := cpu.extensions.Add(ext)
+	if err != nil {
+		return 0, err
+	}
+
+	cpu.extensionGen++
+	cpu.FlushDecodeCache()
+	return id, nil
+}
+
+func (cpu *Cpu) RemoveExtension(id ExtensionID) {
+	if cpu.extensions == nil {
+		return
+	}
+
+	cpu.extensions.Remove(id)
+	if cpu.extensions.Len() == 0 {
+		cpu.extensions = nil
+	}
+
+	cpu.extensionGen++
+	cpu.FlushDecodeCache()
+}
+
+// The Cpu's extensions, for decoding its opcodes elsewhere - nil if it has none
+func (cpu *Cpu) Extensions() *Extensions {
+	return cpu.extensions
+}
+
+// Decodes opcode as this Cpu would execute it
+func (cpu *Cpu) Decode(opcode uint16) Instruction {
+	return cpu.extensions.Decode(opcode)
+}
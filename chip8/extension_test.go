@@ -0,0 +1,193 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+func nopExtensionHandler(cpu *Cpu, inst Instruction) error {
+	return nil
+}
+
+// 5xy1 - skip if Vx > Vy, as in CHIP-8E
+var testSGT = Extension{
+	Name:     "test",
+	Mask:     0xF00F,
+	Value:    0x5001,
+	Priority: PriorityFallback,
+	Mnemonic: "SGT",
+	Format:   "V%[1]X, V%[2]X",
+	Handler: func(cpu *Cpu, inst Instruction) error {
+		if cpu.V[inst.X] > cpu.V[inst.Y] {
+			cpu.PC += 2
+		}
+		return nil
+	},
+}
+
+func TestExtensions_Decode(t *testing.T) {
+	override := Extension{Mask: 0xF00F, Value: 0x8004, Mnemonic: "ADDS", Handler: nopExtensionHandler}
+	fallback := Extension{Mask: 0xF000, Value: 0x8000, Priority: PriorityFallback, Mnemonic: "F8", Handler: nopExtensionHandler}
+	newer := Extension{Mask: 0xFF0F, Value: 0x8104, Mnemonic: "NEWER", Handler: nopExtensionHandler}
+
+	tests := map[string]struct {
+		exts         []Extension
+		opcode       uint16
+		wantMnemonic string
+	}{
+		"none":                  {opcode: 0x8124, wantMnemonic: "ADD"},
+		"override":              {exts: []Extension{override}, opcode: 0x8124, wantMnemonic: "ADDS"},
+		"override no match":     {exts: []Extension{override}, opcode: 0x8125, wantMnemonic: "SUB"},
+		"fallback invalid":      {exts: []Extension{fallback}, opcode: 0x8128, wantMnemonic: "F8"},
+		"fallback built-in":     {exts: []Extension{fallback}, opcode: 0x8124, wantMnemonic: "ADD"},
+		"override beats later":  {exts: []Extension{override, fallback}, opcode: 0x8124, wantMnemonic: "ADDS"},
+		"most recent wins":      {exts: []Extension{override, newer}, opcode: 0x8124, wantMnemonic: "NEWER"},
+		"most recent no match":  {exts: []Extension{override, newer}, opcode: 0x8224, wantMnemonic: "ADDS"},
+		"fallback over invalid": {exts: []Extension{testSGT}, opcode: 0x5121, wantMnemonic: "SGT"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var exts *Extensions
+			if test.exts != nil {
+				exts = new(Extensions)
+			}
+			for _, ext := range test.exts {
+				_, err := exts.Add(ext)
+				if err != nil {
+					t.Fatalf("Extensions.Add() error = %v", err)
+				}
+			}
+
+			inst := exts.Decode(test.opcode)
+			if got := strings.Fields(inst.String())[0]; got != test.wantMnemonic {
+				t.Errorf("Extensions.Decode(%04X) = %v, want mnemonic %v", test.opcode, inst, test.wantMnemonic)
+			}
+			if (inst.Op == OpExtension) != (inst.Ext != nil) || inst.Opcode != test.opcode || inst.Length != 2 {
+				t.Errorf("Extensions.Decode(%04X) = %+v, want Ext set exactly for OpExtension", test.opcode, inst)
+			}
+		})
+	}
+}
+
+func TestExtensions_Add(t *testing.T) {
+	tests := map[string]struct {
+		ext     Extension
+		wantErr bool
+	}{
+		"valid":          {ext: testSGT},
+		"no handler":     {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X"}, wantErr: true},
+		"no mnemonic":    {ext: Extension{Mask: 0xF000, Value: 0x5000, Handler: nopExtensionHandler}, wantErr: true},
+		"value off mask": {ext: Extension{Mask: 0xF000, Value: 0x5001, Mnemonic: "X", Handler: nopExtensionHandler}, wantErr: true},
+		"bad priority":   {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X", Priority: 7, Handler: nopExtensionHandler}, wantErr: true},
+		"unindexed verb": {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X", Format: "V%X", Handler: nopExtensionHandler}, wantErr: true},
+		"no verbs":       {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X", Format: "[I]", Handler: nopExtensionHandler}, wantErr: true},
+		"bad octo":       {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X", OctoFormat: "v%X += 1", Handler: nopExtensionHandler}, wantErr: true},
+		"octo no verbs":  {ext: Extension{Mask: 0xF000, Value: 0x5000, Mnemonic: "X", OctoFormat: "clear", Handler: nopExtensionHandler}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := new(Extensions).Add(test.ext)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Extensions.Add() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCpu_AddExtension(t *testing.T) {
+	cpu := NewCpu()
+	cpu.EnableDecodeCache()
+	cpu.Trace = NewTrace(4)
+	cpu.V[1], cpu.V[2] = 5, 3
+	cpu.LoadROM([]byte{0x51, 0x21}) // SGT V1, V2
+
+	cpu.Tick()
+	if cpu.PC != 0x202 {
+		t.Fatalf("5121 without the extension left PC = %03X, want 202", cpu.PC)
+	}
+
+	id, err := cpu.AddExtension(testSGT)
+	if err != nil {
+		t.Fatalf("Cpu.AddExtension() error = %v", err)
+	}
+
+	cpu.PC = 0x200
+	cpu.Tick()
+	if cpu.PC != 0x204 {
+		t.Errorf("SGT V1, V2 with V1 > V2 left PC = %03X, want 204", cpu.PC)
+	}
+
+	entries := cpu.Trace.Entries()
+	if got := entries[len(entries)-1].String(); got != "0200: 5121  SGT V1, V2" {
+		t.Errorf("TraceEntry.String() = %q, want the extension's mnemonic", got)
+	}
+
+	cpu.RemoveExtension(id)
+	if cpu.Extensions() != nil {
+		t.Errorf("Cpu.Extensions() = %v after removing the only extension, want nil", cpu.Extensions())
+	}
+
+	cpu.PC = 0x200
+	cpu.Tick()
+	if cpu.PC != 0x202 {
+		t.Errorf("5121 after RemoveExtension() left PC = %03X, want 202", cpu.PC)
+	}
+}
+
+func TestExtension_BlockEngine(t *testing.T) {
+	cpu := NewCpu()
+	cpu.V[1], cpu.V[2] = 5, 3
+	cpu.LoadROM([]byte{0x51, 0x21, 0x60, 0x01, 0x60, 0x02}) // SGT V1, V2 / LD V0, 0x01 / LD V0, 0x02
+	cpu.AddExtension(testSGT)
+
+	engine := NewBlockEngine(cpu)
+	defer engine.Close()
+
+	_, err := engine.Step()
+	if err != nil {
+		t.Fatalf("BlockEngine.Step() error = %v", err)
+	}
+	if cpu.PC != 0x204 {
+		t.Errorf("BlockEngine ran SGT V1, V2 to PC = %03X, want 204", cpu.PC)
+	}
+}
+
+// Blocks compiled before an extension changes must not keep running the old instruction set
+func TestExtension_BlockEngineInvalidate(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x01, 0x12, 0x00}) // LD V0, 0x01 / JP 0x200
+
+	engine := NewBlockEngine(cpu)
+	defer engine.Close()
+
+	// Runs the loop once - extended instructions may be interpreted rather than compiled into the block
+	step := func(want uint8) {
+		t.Helper()
+		_, err := engine.Run(2)
+		if err != nil {
+			t.Fatalf("BlockEngine.Run() error = %v", err)
+		}
+		if cpu.V[0] != want || cpu.PC != 0x200 {
+			t.Errorf("BlockEngine.Run() left V0 = %v, PC = %03X, want %v and 200", cpu.V[0], cpu.PC, want)
+		}
+	}
+
+	step(1)
+
+	id, err := cpu.AddExtension(Extension{
+		Mask: 0xF000, Value: 0x6000, Mnemonic: "LDI", Format: "V%[1]X, 0x%02[4]X",
+		Handler: func(cpu *Cpu, inst Instruction) error {
+			cpu.V[inst.X] = inst.NN + 1
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Cpu.AddExtension() error = %v", err)
+	}
+	step(2)
+
+	cpu.RemoveExtension(id)
+	step(1)
+}
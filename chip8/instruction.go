@@ -0,0 +1,344 @@
+package chip8
+
+import "fmt"
+
+type Op uint8
+
+const (
+	OpInvalid Op = iota
+
+	// CHIP-8
+	OpSYS
+	OpCLS
+	OpRET
+	OpJP
+	OpCALL
+	OpSE_v_byte
+	OpSNE_v_byte
+	OpSE_v1_v2
+	OpLD_v_byte
+	OpADD_v_byte
+	OpLD_v1_v2
+	OpOR_v1_v2
+	OpAND_v1_v2
+	OpXOR_v1_v2
+	OpADD_v1_v2
+	OpSUB_v1_v2
+	OpSHR_v1_v2
+	OpSUBN_v1_v2
+	OpSHL_v1_v2
+	OpSNE_v1_v2
+	OpLD_i_addr
+	OpJP_v0_addr
+	OpRND_v_byte
+	OpDRW
+	OpSKP
+	OpSKNP
+	OpLD_v_dt
+	OpLD_v_k
+	OpLD_dt_v
+	OpLD_st_v
+	OpADD_i_v
+	OpLD_f_v
+	OpLD_b_v
+	OpLD_mem_v
+	OpLD_v_mem
+
+	// SUPER-CHIP
+	OpSCD
+	OpSCR
+	OpSCL
+	OpEXIT
+	OpLOW
+	OpHIGH
+	OpLD_hf_v
+	OpLD_r_v
+	OpLD_v_r
+
+	// XO-CHIP
+	OpSCU
+	OpLD_mem_range
+	OpLD_range_mem
+	OpLD_i_long
+	OpPLANE
+	OpAUDIO
+	OpPITCH
+
+	// Decoded by an Extension - see Instruction.Ext
+	OpExtension
+
+	opCount
+)
+
+// Decoded form of an opcode. Fields that the op does not use are still filled from the opcode bits.
+type Instruction struct {
+	Op     Op
+	Opcode uint16
+	X      uint8  // Register index from bits 8-11
+	Y      uint8  // Register index from bits 4-7
+	N      uint8  // 4-bit immediate from bits 0-3
+	NN     uint8  // 8-bit immediate from bits 0-7
+	NNN    uint16 // 12-bit address from bits 0-11 - for 4-byte instructions, the caller sets this to the second word
+	Length uint8  // Instruction length in bytes
+
+	Ext *Extension // Set when Op is OpExtension
+}
+
+type opInfo struct {
+	mnemonic string
+	format   string // Classic syntax operands - %[1]X is X, %[2]X is Y, %[3]d is N, %02[4]X is NN, %[5]s is the address
+}
+
+var ops = [opCount]opInfo{
+	OpInvalid:      {"???", ""},
+	OpSYS:          {"SYS", "%[5]s"},
+	OpCLS:          {"CLS", ""},
+	OpRET:          {"RET", ""},
+	OpJP:           {"JP", "%[5]s"},
+	OpCALL:         {"CALL", "%[5]s"},
+	OpSE_v_byte:    {"SE", "V%[1]X, 0x%02[4]X"},
+	OpSNE_v_byte:   {"SNE", "V%[1]X, 0x%02[4]X"},
+	OpSE_v1_v2:     {"SE", "V%[1]X, V%[2]X"},
+	OpLD_v_byte:    {"LD", "V%[1]X, 0x%02[4]X"},
+	OpADD_v_byte:   {"ADD", "V%[1]X, 0x%02[4]X"},
+	OpLD_v1_v2:     {"LD", "V%[1]X, V%[2]X"},
+	OpOR_v1_v2:     {"OR", "V%[1]X, V%[2]X"},
+	OpAND_v1_v2:    {"AND", "V%[1]X, V%[2]X"},
+	OpXOR_v1_v2:    {"XOR", "V%[1]X, V%[2]X"},
+	OpADD_v1_v2:    {"ADD", "V%[1]X, V%[2]X"},
+	OpSUB_v1_v2:    {"SUB", "V%[1]X, V%[2]X"},
+	OpSHR_v1_v2:    {"SHR", "V%[1]X, V%[2]X"},
+	OpSUBN_v1_v2:   {"SUBN", "V%[1]X, V%[2]X"},
+	OpSHL_v1_v2:    {"SHL", "V%[1]X, V%[2]X"},
+	OpSNE_v1_v2:    {"SNE", "V%[1]X, V%[2]X"},
+	OpLD_i_addr:    {"LD", "I, %[5]s"},
+	OpJP_v0_addr:   {"JP", "V0, %[5]s"},
+	OpRND_v_byte:   {"RND", "V%[1]X, 0x%02[4]X"},
+	OpDRW:          {"DRW", "V%[1]X, V%[2]X, %[3]d"},
+	OpSKP:          {"SKP", "V%[1]X"},
+	OpSKNP:         {"SKNP", "V%[1]X"},
+	OpLD_v_dt:      {"LD", "V%[1]X, DT"},
+	OpLD_v_k:       {"LD", "V%[1]X, K"},
+	OpLD_dt_v:      {"LD", "DT, V%[1]X"},
+	OpLD_st_v:      {"LD", "ST, V%[1]X"},
+	OpADD_i_v:      {"ADD", "I, V%[1]X"},
+	OpLD_f_v:       {"LD", "F, V%[1]X"},
+	OpLD_b_v:       {"LD", "B, V%[1]X"},
+	OpLD_mem_v:     {"LD", "[I], V%[1]X"},
+	OpLD_v_mem:     {"LD", "V%[1]X, [I]"},
+	OpSCD:          {"SCD", "%[3]d"},
+	OpSCR:          {"SCR", ""},
+	OpSCL:          {"SCL", ""},
+	OpEXIT:         {"EXIT", ""},
+	OpLOW:          {"LOW", ""},
+	OpHIGH:         {"HIGH", ""},
+	OpLD_hf_v:      {"LD", "HF, V%[1]X"},
+	OpLD_r_v:       {"LD", "R, V%[1]X"},
+	OpLD_v_r:       {"LD", "V%[1]X, R"},
+	OpSCU:          {"SCU", "%[3]d"},
+	OpLD_mem_range: {"LD", "[I], V%[1]X-V%[2]X"},
+	OpLD_range_mem: {"LD", "V%[1]X-V%[2]X, [I]"},
+	OpLD_i_long:    {"LD", "I, %[5]s"},
+	OpPLANE:        {"PLANE", "%[1]d"},
+	OpAUDIO:        {"AUDIO", ""},
+	OpPITCH:        {"PITCH", "V%[1]X"},
+	OpExtension:    {"EXT", ""},
+}
+
+func (op Op) String() string {
+	if op >= opCount {
+		return ops[OpInvalid].mnemonic
+	}
+	return ops[op].mnemonic
+}
+
+// Ops whose address operand is a jump or call target
+func (op Op) IsBranch() bool {
+	return op == OpJP || op == OpCALL || op == OpJP_v0_addr
+}
+
+// Op for every opcode, precomputed from decodeOp so Decode is a single lookup
+var opTable [0x10000]Op
+
+func init() {
+	for i := range opTable {
+		opTable[i] = decodeOp(uint16(i))
+	}
+}
+
+func Decode(opcode uint16) Instruction {
+	inst := Instruction{
+		Op:     opTable[opcode],
+		Opcode: opcode,
+		X:      uint8((opcode & 0x0F00) >> 8),
+		Y:      uint8((opcode & 0x00F0) >> 4),
+		N:      uint8(opcode & 0x000F),
+		NN:     uint8(opcode & 0x00FF),
+		NNN:    opcode & 0x0FFF,
+		Length: 2,
+	}
+
+	if inst.Op == OpLD_i_long {
+		inst.NNN = 0
+		inst.Length = 4
+	}
+
+	return inst
+}
+
+func decodeOp(opcode uint16) Op {
+	n := opcode & 0x000F
+	nn := opcode & 0x00FF
+	op := OpInvalid
+
+	switch opcode & 0xF000 {
+	case 0x0000:
+		switch {
+		case opcode&0xF0FF == 0x00E0: // The second nibble is ignored, as the original executor did
+			op = OpCLS
+		case opcode&0xF0FF == 0x00EE:
+			op = OpRET
+		case opcode&0xFFF0 == 0x00C0:
+			op = OpSCD
+		case opcode&0xFFF0 == 0x00D0:
+			op = OpSCU
+		case opcode == 0x00FB:
+			op = OpSCR
+		case opcode == 0x00FC:
+			op = OpSCL
+		case opcode == 0x00FD:
+			op = OpEXIT
+		case opcode == 0x00FE:
+			op = OpLOW
+		case opcode == 0x00FF:
+			op = OpHIGH
+		default:
+			op = OpSYS
+		}
+	case 0x1000:
+		op = OpJP
+	case 0x2000:
+		op = OpCALL
+	case 0x3000:
+		op = OpSE_v_byte
+	case 0x4000:
+		op = OpSNE_v_byte
+	case 0x5000:
+		switch n {
+		case 0x0:
+			op = OpSE_v1_v2
+		case 0x2:
+			op = OpLD_mem_range
+		case 0x3:
+			op = OpLD_range_mem
+		}
+	case 0x6000:
+		op = OpLD_v_byte
+	case 0x7000:
+		op = OpADD_v_byte
+	case 0x8000:
+		switch n {
+		case 0x0:
+			op = OpLD_v1_v2
+		case 0x1:
+			op = OpOR_v1_v2
+		case 0x2:
+			op = OpAND_v1_v2
+		case 0x3:
+			op = OpXOR_v1_v2
+		case 0x4:
+			op = OpADD_v1_v2
+		case 0x5:
+			op = OpSUB_v1_v2
+		case 0x6:
+			op = OpSHR_v1_v2
+		case 0x7:
+			op = OpSUBN_v1_v2
+		case 0xE:
+			op = OpSHL_v1_v2
+		}
+	case 0x9000:
+		if n == 0x0 {
+			op = OpSNE_v1_v2
+		}
+	case 0xA000:
+		op = OpLD_i_addr
+	case 0xB000:
+		op = OpJP_v0_addr
+	case 0xC000:
+		op = OpRND_v_byte
+	case 0xD000:
+		op = OpDRW
+	case 0xE000:
+		switch nn {
+		case 0x9E:
+			op = OpSKP
+		case 0xA1:
+			op = OpSKNP
+		}
+	case 0xF000:
+		switch nn {
+		case 0x00:
+			if opcode == 0xF000 {
+				op = OpLD_i_long
+			}
+		case 0x01:
+			op = OpPLANE
+		case 0x02:
+			if opcode == 0xF002 {
+				op = OpAUDIO
+			}
+		case 0x07:
+			op = OpLD_v_dt
+		case 0x0A:
+			op = OpLD_v_k
+		case 0x15:
+			op = OpLD_dt_v
+		case 0x18:
+			op = OpLD_st_v
+		case 0x1E:
+			op = OpADD_i_v
+		case 0x29:
+			op = OpLD_f_v
+		case 0x30:
+			op = OpLD_hf_v
+		case 0x33:
+			op = OpLD_b_v
+		case 0x3A:
+			op = OpPITCH
+		case 0x55:
+			op = OpLD_mem_v
+		case 0x65:
+			op = OpLD_v_mem
+		case 0x75:
+			op = OpLD_r_v
+		case 0x85:
+			op = OpLD_v_r
+		}
+	}
+
+	return op
+}
+
+// Classic syntax, with target in place of the address operand
+func (inst Instruction) Format(target string) string {
+	info := ops[OpInvalid]
+	if inst.Ext != nil {
+		info = opInfo{inst.Ext.Mnemonic, inst.Ext.Format}
+	} else if inst.Op < opCount {
+		info = ops[inst.Op]
+	}
+
+	if info.format == "" {
+		return info.mnemonic
+	}
+
+	return info.mnemonic + " " + fmt.Sprintf(info.format, inst.X, inst.Y, inst.N, inst.NN, target)
+}
+
+func (inst Instruction) String() string {
+	if inst.Length == 4 {
+		return inst.Format(fmt.Sprintf("0x%04X", inst.NNN))
+	}
+	return inst.Format(fmt.Sprintf("0x%03X", inst.NNN))
+}
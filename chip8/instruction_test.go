@@ -0,0 +1,141 @@
+package chip8
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// Reference patterns, independent of the decoder's switch
+var opPatterns = map[Op]struct {
+	mask  uint16
+	value uint16
+}{
+	OpSYS:          {0xF000, 0x0000},
+	OpCLS:          {0xF0FF, 0x00E0},
+	OpRET:          {0xF0FF, 0x00EE},
+	OpJP:           {0xF000, 0x1000},
+	OpCALL:         {0xF000, 0x2000},
+	OpSE_v_byte:    {0xF000, 0x3000},
+	OpSNE_v_byte:   {0xF000, 0x4000},
+	OpSE_v1_v2:     {0xF00F, 0x5000},
+	OpLD_v_byte:    {0xF000, 0x6000},
+	OpADD_v_byte:   {0xF000, 0x7000},
+	OpLD_v1_v2:     {0xF00F, 0x8000},
+	OpOR_v1_v2:     {0xF00F, 0x8001},
+	OpAND_v1_v2:    {0xF00F, 0x8002},
+	OpXOR_v1_v2:    {0xF00F, 0x8003},
+	OpADD_v1_v2:    {0xF00F, 0x8004},
+	OpSUB_v1_v2:    {0xF00F, 0x8005},
+	OpSHR_v1_v2:    {0xF00F, 0x8006},
+	OpSUBN_v1_v2:   {0xF00F, 0x8007},
+	OpSHL_v1_v2:    {0xF00F, 0x800E},
+	OpSNE_v1_v2:    {0xF00F, 0x9000},
+	OpLD_i_addr:    {0xF000, 0xA000},
+	OpJP_v0_addr:   {0xF000, 0xB000},
+	OpRND_v_byte:   {0xF000, 0xC000},
+	OpDRW:          {0xF000, 0xD000},
+	OpSKP:          {0xF0FF, 0xE09E},
+	OpSKNP:         {0xF0FF, 0xE0A1},
+	OpLD_v_dt:      {0xF0FF, 0xF007},
+	OpLD_v_k:       {0xF0FF, 0xF00A},
+	OpLD_dt_v:      {0xF0FF, 0xF015},
+	OpLD_st_v:      {0xF0FF, 0xF018},
+	OpADD_i_v:      {0xF0FF, 0xF01E},
+	OpLD_f_v:       {0xF0FF, 0xF029},
+	OpLD_b_v:       {0xF0FF, 0xF033},
+	OpLD_mem_v:     {0xF0FF, 0xF055},
+	OpLD_v_mem:     {0xF0FF, 0xF065},
+	OpSCD:          {0xFFF0, 0x00C0},
+	OpSCR:          {0xFFFF, 0x00FB},
+	OpSCL:          {0xFFFF, 0x00FC},
+	OpEXIT:         {0xFFFF, 0x00FD},
+	OpLOW:          {0xFFFF, 0x00FE},
+	OpHIGH:         {0xFFFF, 0x00FF},
+	OpLD_hf_v:      {0xF0FF, 0xF030},
+	OpLD_r_v:       {0xF0FF, 0xF075},
+	OpLD_v_r:       {0xF0FF, 0xF085},
+	OpSCU:          {0xFFF0, 0x00D0},
+	OpLD_mem_range: {0xF00F, 0x5002},
+	OpLD_range_mem: {0xF00F, 0x5003},
+	OpLD_i_long:    {0xFFFF, 0xF000},
+	OpPLANE:        {0xF0FF, 0xF001},
+	OpAUDIO:        {0xFFFF, 0xF002},
+	OpPITCH:        {0xF0FF, 0xF03A},
+}
+
+func TestDecode(t *testing.T) {
+	seen := make(map[Op]bool)
+
+	for i := range 0x10000 {
+		opcode := uint16(i)
+		inst := Decode(opcode)
+
+		// The most specific matching pattern wins
+		want := OpInvalid
+		wantBits := -1
+		for op, pattern := range opPatterns {
+			if opcode&pattern.mask == pattern.value && bits.OnesCount16(pattern.mask) > wantBits {
+				want = op
+				wantBits = bits.OnesCount16(pattern.mask)
+			}
+		}
+
+		if inst.Op != want {
+			t.Fatalf("Decode(%04X).Op = %v (%d), want %v (%d)", opcode, inst.Op, inst.Op, want, want)
+		}
+		seen[inst.Op] = true
+
+		if inst.Opcode != opcode || inst.X != uint8(opcode>>8&0xF) || inst.Y != uint8(opcode>>4&0xF) ||
+			inst.N != uint8(opcode&0xF) || inst.NN != uint8(opcode&0xFF) {
+			t.Fatalf("Decode(%04X) operand fields wrong: %+v", opcode, inst)
+		}
+
+		wantLength, wantNNN := uint8(2), opcode&0xFFF
+		if want == OpLD_i_long {
+			wantLength, wantNNN = 4, 0
+		}
+		if inst.Length != wantLength || inst.NNN != wantNNN {
+			t.Fatalf("Decode(%04X) Length = %v, NNN = %03X, want %v, %03X", opcode, inst.Length, inst.NNN, wantLength, wantNNN)
+		}
+
+		if inst.Op.String() != ops[inst.Op].mnemonic || inst.String() == "" {
+			t.Fatalf("Decode(%04X) has no mnemonic", opcode)
+		}
+	}
+
+	for op := OpInvalid; op < OpExtension; op++ {
+		if !seen[op] {
+			t.Errorf("Decode() never produced op %v (%d)", op, op)
+		}
+	}
+}
+
+func TestInstruction_String(t *testing.T) {
+	tests := map[uint16]string{
+		0x00E0: "CLS",
+		0x00EE: "RET",
+		0x01E0: "CLS",
+		0x0FEE: "RET",
+		0x02A0: "SYS 0x2A0",
+		0x1234: "JP 0x234",
+		0x3A12: "SE VA, 0x12",
+		0x6120: "LD V1, 0x20",
+		0x8124: "ADD V1, V2",
+		0x8128: "???",
+		0xA123: "LD I, 0x123",
+		0xB300: "JP V0, 0x300",
+		0xD125: "DRW V1, V2, 5",
+		0xE59E: "SKP V5",
+		0xF729: "LD F, V7",
+		0xF755: "LD [I], V7",
+		0x00C4: "SCD 4",
+		0x5122: "LD [I], V1-V2",
+		0xF301: "PLANE 3",
+		0xF000: "LD I, 0x0000",
+	}
+	for opcode, want := range tests {
+		if got := Decode(opcode).String(); got != want {
+			t.Errorf("Decode(%04X).String() = %q, want %q", opcode, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package chip8
+
+import "fmt"
+
+const KeyCount = 0x10
+
+// Number of hex keypads - the second is only read by variants such as CHIP-8X
+const PadCount = 2
+
+type Keypad struct {
+	// One bit per key, bit n set while key n is held
+	keys [PadCount]uint16
+}
+
+func NewKeypad() *Keypad {
+	return new(Keypad)
+}
+
+func (keypad *Keypad) Press(key uint8) error {
+	return keypad.PressPad(0, key)
+}
+
+func (keypad *Keypad) Release(key uint8) error {
+	return keypad.ReleasePad(0, key)
+}
+
+func (keypad *Keypad) IsPressed(key uint8) (bool, error) {
+	return keypad.IsPressedPad(0, key)
+}
+
+func checkKey(pad int, key uint8) error {
+	if pad < 0 || pad >= PadCount {
+		return fmt.Errorf("keypad out of range: %v", pad)
+	}
+	if key >= KeyCount {
+		return fmt.Errorf("key out of range: %v", key)
+	}
+	return nil
+}
+
+func (keypad *Keypad) PressPad(pad int, key uint8) error {
+	err := checkKey(pad, key)
+	if err != nil {
+		return err
+	}
+
+	keypad.keys[pad] |= 1 << key
+
+	return nil
+}
+
+func (keypad *Keypad) ReleasePad(pad int, key uint8) error {
+	err := checkKey(pad, key)
+	if err != nil {
+		return err
+	}
+
+	keypad.keys[pad] &^= 1 << key
+
+	return nil
+}
+
+func (keypad *Keypad) IsPressedPad(pad int, key uint8) (bool, error) {
+	err := checkKey(pad, key)
+	if err != nil {
+		return false, err
+	}
+
+	return keypad.keys[pad]&(1<<key) != 0, nil
+}
+
+// State of the first keypad
+func (keypad *Keypad) State() uint16 {
+	return keypad.keys[0]
+}
+
+func (keypad *Keypad) SetState(keys uint16) {
+	keypad.keys[0] = keys
+}
+
+// State of every keypad, indexed by pad
+func (keypad *Keypad) Pads() [PadCount]uint16 {
+	return keypad.keys
+}
+
+func (keypad *Keypad) SetPads(pads [PadCount]uint16) {
+	keypad.keys = pads
+}
@@ -0,0 +1,96 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestKeypad(t *testing.T) {
+	tests := map[string]struct {
+		press   []uint8
+		release []uint8
+		key     uint8
+		want    bool
+		wantErr bool
+	}{
+		"default": {
+			key:  0x5,
+			want: false,
+		},
+		"pressed": {
+			press: []uint8{0x3, 0xA},
+			key:   0xA,
+			want:  true,
+		},
+		"released": {
+			press:   []uint8{0x3, 0xA},
+			release: []uint8{0xA},
+			key:     0xA,
+			want:    false,
+		},
+		"out of range": {
+			key:     0x10,
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			keypad := NewKeypad()
+
+			for _, key := range test.press {
+				keypad.Press(key)
+			}
+			for _, key := range test.release {
+				keypad.Release(key)
+			}
+
+			got, err := keypad.IsPressed(test.key)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Keypad.IsPressed() error = %v, wantErr %v", err, test.wantErr)
+				return
+			}
+			if got != test.want {
+				t.Errorf("Keypad.IsPressed() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKeypad_State(t *testing.T) {
+	keypad := NewKeypad()
+	keypad.Press(0x0)
+	keypad.Press(0xF)
+
+	if keypad.State() != 0x8001 {
+		t.Errorf("Keypad.State() = %04X, want 8001", keypad.State())
+	}
+
+	keypad.SetState(0x0010)
+	if pressed, _ := keypad.IsPressed(0x4); !pressed {
+		t.Errorf("Keypad.SetState() did not press key 4")
+	}
+}
+
+func TestKeypad_Pads(t *testing.T) {
+	keypad := NewKeypad()
+	keypad.PressPad(1, 0x7)
+
+	if pressed, _ := keypad.IsPressedPad(1, 0x7); !pressed {
+		t.Errorf("Keypad.IsPressedPad(1, 7) = false after PressPad")
+	}
+	if pressed, _ := keypad.IsPressed(0x7); pressed {
+		t.Errorf("Keypad.IsPressed(7) = true, pressing the second pad pressed the first")
+	}
+	if keypad.State() != 0 {
+		t.Errorf("Keypad.State() = %04X, want the first pad only", keypad.State())
+	}
+
+	keypad.ReleasePad(1, 0x7)
+	if pressed, _ := keypad.IsPressedPad(1, 0x7); pressed {
+		t.Errorf("Keypad.IsPressedPad(1, 7) = true after ReleasePad")
+	}
+
+	if _, err := keypad.IsPressedPad(PadCount, 0x0); err == nil {
+		t.Errorf("Keypad.IsPressedPad(%v, 0) error = %v, wantErr true", PadCount, err)
+	}
+}
@@ -0,0 +1,309 @@
+package chip8
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const FrameRate = 60
+const frameDuration = time.Second / FrameRate
+
+// Frames a throttled Machine may fall behind before it gives up catching up and resets its pace
+const maxFrameLag = 5
+
+type EventKind int
+
+const (
+	EventFrame    EventKind = iota // A frame has finished - Frame is its number
+	EventSoundOn                   // ST became non-zero
+	EventSoundOff                  // ST reached zero
+	EventError                     // Err stopped the machine - EventHalted follows
+	EventHalted                    // The machine has stopped - always the last event before Events is closed
+)
+
+func (kind EventKind) String() string {
+	switch kind {
+	case EventFrame:
+		return "frame"
+	case EventSoundOn:
+		return "sound on"
+	case EventSoundOff:
+		return "sound off"
+	case EventError:
+		return "error"
+	case EventHalted:
+		return "halted"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(kind))
+}
+
+type Event struct {
+	Kind  EventKind
+	Frame uint64 // Frames run when the event was sent
+	Err   error  // Set for EventError
+}
+
+// Machine runs a Scheduler on its own goroutine, paced to real time, for embedding in frontends and tools.
+// All its methods are safe to call from any goroutine while it runs.
+type Machine struct {
+	scheduler *Scheduler
+	frames    *FrameBuffer
+
+	mu      sync.Mutex // Guards the scheduler and its Cpu, and the fields below
+	paused  bool
+	steps   int     // Frames still to run while paused
+	speed   float64 // Multiple of real time - 0 runs unthrottled
+	started bool
+	sound   bool
+
+	wake   chan struct{}
+	events chan Event
+	done   chan struct{}
+	err    error // Set before done is closed
+}
+
+// Takes ownership of the scheduler and its Cpu - configure and subscribe to it before calling Start,
+// and use Do to reach them afterwards
+func NewMachine(scheduler *Scheduler) *Machine {
+	frames := NewFrameBuffer()
+	scheduler.Subscribe(frames)
+
+	return &Machine{
+		scheduler: scheduler,
+		frames:    frames,
+		speed:     1,
+		wake:      make(chan struct{}, 1),
+		events:    make(chan Event, 64),
+		done:      make(chan struct{}),
+	}
+}
+
+// Events are sent in order, and the machine never waits for them to be received. Frame events are dropped
+// while the channel is full, as the next one supersedes them; other events make room by dropping the oldest.
+// EventError and EventHalted are always delivered, and the channel is closed after EventHalted.
+func (machine *Machine) Events() <-chan Event {
+	return machine.events
+}
+
+// Closed once the machine has stopped
+func (machine *Machine) Done() <-chan struct{} {
+	return machine.done
+}
+
+// Waits for the machine to stop, returning the error that stopped it, or ctx.Err() if it was cancelled
+func (machine *Machine) Wait() error {
+	<-machine.done
+	return machine.err
+}
+
+// Starts running frames until ctx is done or a frame fails
+func (machine *Machine) Start(ctx context.Context) error {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+
+	if machine.started {
+		return fmt.Errorf("machine already started")
+	}
+	machine.started = true
+
+	go machine.run(ctx)
+
+	return nil
+}
+
+func (machine *Machine) Pause() {
+	machine.mu.Lock()
+	machine.paused = true
+	machine.mu.Unlock()
+	machine.signal()
+}
+
+func (machine *Machine) Resume() {
+	machine.mu.Lock()
+	machine.paused = false
+	machine.steps = 0
+	machine.mu.Unlock()
+	machine.signal()
+}
+
+// Pauses the machine, then runs a single frame
+func (machine *Machine) Step() {
+	machine.mu.Lock()
+	machine.paused = true
+	machine.steps++
+	machine.mu.Unlock()
+	machine.signal()
+}
+
+// Sets the pace as a multiple of real time - 1 is 60 frames per second, 0 runs as fast as possible
+func (machine *Machine) SetSpeed(speed float64) error {
+	if speed < 0 {
+		return fmt.Errorf("speed must not be negative: %v", speed)
+	}
+
+	machine.mu.Lock()
+	machine.speed = speed
+	machine.mu.Unlock()
+	machine.signal()
+
+	return nil
+}
+
+func (machine *Machine) PressKey(key uint8) error {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+	return machine.scheduler.cpu.Keypad.Press(key)
+}
+
+func (machine *Machine) ReleaseKey(key uint8) error {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+	return machine.scheduler.cpu.Keypad.Release(key)
+}
+
+// The display as of the last complete frame - see FrameBuffer.Snapshot
+func (machine *Machine) Snapshot() Frame {
+	return machine.frames.Snapshot()
+}
+
+// Runs fn between frames with exclusive access to the Cpu and Scheduler, which must not be kept after fn returns
+func (machine *Machine) Do(fn func(cpu *Cpu, scheduler *Scheduler) error) error {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+	return fn(machine.scheduler.cpu, machine.scheduler)
+}
+
+func (machine *Machine) signal() {
+	select {
+	case machine.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (machine *Machine) run(ctx context.Context) {
+	err := machine.loop(ctx)
+	if err != nil && ctx.Err() == nil {
+		machine.send(Event{Kind: EventError, Frame: machine.frame(), Err: err})
+	}
+
+	machine.err = err
+	machine.send(Event{Kind: EventHalted, Frame: machine.frame()})
+	close(machine.events)
+	close(machine.done)
+}
+
+func (machine *Machine) loop(ctx context.Context) error {
+	next := time.Now()
+	timer := time.NewTimer(frameDuration)
+	defer timer.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		run, speed := machine.runnable()
+		if !run {
+			select {
+			case <-ctx.Done():
+			case <-machine.wake:
+			}
+			next = time.Now()
+			continue
+		}
+
+		err := machine.runFrame()
+		if err != nil {
+			return err
+		}
+
+		if speed == 0 {
+			next = time.Now()
+			continue
+		}
+
+		next = next.Add(time.Duration(float64(frameDuration) / speed))
+		wait := time.Until(next)
+		if wait < -maxFrameLag*frameDuration {
+			next = time.Now()
+		}
+		if wait <= 0 {
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+		case <-machine.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Whether a frame should run now, consuming a step if paused
+func (machine *Machine) runnable() (bool, float64) {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+
+	if machine.paused {
+		if machine.steps == 0 {
+			return false, machine.speed
+		}
+		machine.steps--
+	}
+	return true, machine.speed
+}
+
+func (machine *Machine) runFrame() error {
+	machine.mu.Lock()
+	err := machine.scheduler.RunFrame()
+	frame := machine.scheduler.Frame()
+	sound := machine.scheduler.cpu.ST > 0
+	changed := sound != machine.sound
+	machine.sound = sound
+	machine.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		kind := EventSoundOff
+		if sound {
+			kind = EventSoundOn
+		}
+		machine.send(Event{Kind: kind, Frame: frame})
+	}
+
+	select {
+	case machine.events <- Event{Kind: EventFrame, Frame: frame}:
+	default:
+	}
+
+	return nil
+}
+
+// Sends without blocking, dropping the oldest unreceived events until there is room
+func (machine *Machine) send(event Event) {
+	for {
+		select {
+		case machine.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-machine.events:
+		default:
+		}
+	}
+}
+
+func (machine *Machine) frame() uint64 {
+	machine.mu.Lock()
+	defer machine.mu.Unlock()
+	return machine.scheduler.Frame()
+}
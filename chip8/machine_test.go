@@ -0,0 +1,244 @@
+package chip8
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestMachine() *Machine {
+	return NewMachine(NewScheduler(newSchedulerTestCpu()))
+}
+
+func nextEvent(t *testing.T, machine *Machine) Event {
+	t.Helper()
+
+	event, ok := <-machine.Events()
+	if !ok {
+		t.Fatalf("Machine.Events() closed early")
+	}
+	return event
+}
+
+func TestMachine_Step(t *testing.T) {
+	machine := newTestMachine()
+	machine.Do(func(cpu *Cpu, scheduler *Scheduler) error {
+		cpu.ST = 3
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	machine.Pause()
+	err := machine.Start(ctx)
+	if err != nil {
+		t.Fatalf("Machine.Start() error = %v", err)
+	}
+
+	var got []EventKind
+	for range 3 {
+		machine.Step()
+		for {
+			event := nextEvent(t, machine)
+			got = append(got, event.Kind)
+			if event.Kind == EventFrame {
+				break
+			}
+		}
+	}
+
+	want := []EventKind{EventSoundOn, EventFrame, EventFrame, EventSoundOff, EventFrame}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	machine.Do(func(cpu *Cpu, scheduler *Scheduler) error {
+		if scheduler.Frame() != 3 || cpu.V[0] != 11 {
+			t.Errorf("after 3 steps Frame() = %v, V0 = %v, want 3 and 11", scheduler.Frame(), cpu.V[0])
+		}
+		return nil
+	})
+
+	cancel()
+	if err := machine.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Machine.Wait() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestMachine_Error(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x1F, 0xFF}) // JP 0xFFF
+	machine := NewMachine(NewScheduler(cpu))
+	machine.SetSpeed(0)
+
+	machine.Start(context.Background())
+
+	var kinds []EventKind
+	var eventErr error
+	for event := range machine.Events() {
+		kinds = append(kinds, event.Kind)
+		if event.Kind == EventError {
+			eventErr = event.Err
+		}
+	}
+
+	if want := []EventKind{EventError, EventHalted}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("events = %v, want %v", kinds, want)
+	}
+	if err := machine.Wait(); err == nil || err != eventErr {
+		t.Errorf("Machine.Wait() error = %v, want the EventError's %v", err, eventErr)
+	}
+}
+
+// Nothing reads Events until the machine has stopped, and the channel starts full
+func TestMachine_Unread(t *testing.T) {
+	tests := map[string]struct {
+		rom       []byte
+		cancel    bool
+		wantKinds []EventKind
+	}{
+		"error":     {rom: []byte{0x1F, 0xFF}, wantKinds: []EventKind{EventError, EventHalted}},
+		"cancelled": {rom: schedulerTestROM, cancel: true, wantKinds: []EventKind{EventHalted}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			cpu.LoadROM(test.rom)
+			machine := NewMachine(NewScheduler(cpu))
+			machine.SetSpeed(0)
+			for len(machine.events) < cap(machine.events) {
+				machine.events <- Event{Kind: EventSoundOn}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			machine.Start(ctx)
+			if test.cancel {
+				cancel()
+			}
+			defer cancel()
+
+			done := make(chan error)
+			go func() { done <- machine.Wait() }()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Errorf("Machine.Wait() error = %v, wantErr true", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Machine.Wait() did not return")
+			}
+
+			var kinds []EventKind
+			for event := range machine.Events() {
+				kinds = append(kinds, event.Kind)
+			}
+			if len(kinds) < len(test.wantKinds) {
+				t.Fatalf("events = %v, want to end with %v", kinds, test.wantKinds)
+			}
+			if got := kinds[len(kinds)-len(test.wantKinds):]; !reflect.DeepEqual(got, test.wantKinds) {
+				t.Errorf("last events = %v, want %v", got, test.wantKinds)
+			}
+		})
+	}
+}
+
+func TestMachine_Start(t *testing.T) {
+	machine := newTestMachine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := machine.Start(ctx)
+	if err != nil {
+		t.Fatalf("Machine.Start() error = %v", err)
+	}
+	if err := machine.Start(ctx); err == nil {
+		t.Errorf("second Machine.Start() error = %v, wantErr true", err)
+	}
+
+	<-machine.Done()
+	var last Event
+	for event := range machine.Events() {
+		last = event
+	}
+	if last.Kind != EventHalted || last.Frame != 0 {
+		t.Errorf("last event = %v at frame %v, want %v at frame 0", last.Kind, last.Frame, EventHalted)
+	}
+}
+
+func TestMachine_SetSpeed(t *testing.T) {
+	tests := map[string]struct {
+		speed   float64
+		wantErr bool
+	}{
+		"real time":   {speed: 1},
+		"double":      {speed: 2},
+		"unthrottled": {speed: 0},
+		"negative":    {speed: -1, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := newTestMachine().SetSpeed(test.speed)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Machine.SetSpeed() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// Run with -race - callers on other goroutines must not race the machine's own
+func TestMachine_Concurrent(t *testing.T) {
+	machine := newTestMachine()
+	machine.SetSpeed(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	machine.Start(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			machine.PressKey(uint8(i % KeyCount))
+			machine.ReleaseKey(uint8(i % KeyCount))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			machine.Snapshot()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			if i%2 == 0 {
+				machine.Pause()
+			} else {
+				machine.Resume()
+			}
+			machine.Step()
+			machine.SetSpeed(float64(i % 3))
+		}
+		machine.SetSpeed(0)
+		machine.Resume()
+	}()
+
+	for event := range machine.Events() {
+		if event.Frame >= 100 {
+			break
+		}
+	}
+	wg.Wait()
+	cancel()
+
+	if err := machine.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Machine.Wait() error = %v, want context.Canceled", err)
+	}
+}
@@ -5,19 +5,48 @@ import (
 	"strings"
 )
 
-const MemorySize = 0x1000
+const MemorySize = 0x1000 // Default CHIP-8 memory size - see Platform for other layouts
 
 type Memory struct {
-	Memory [MemorySize]uint8
+	Memory []uint8
+
+	hooks          *memoryHooks
+	nextHookID     HookID
+	writeObservers []writeObserver // Notified of each byte written through Set8/Set16, and of reloads
+}
+
+type writeObserver interface {
+	invalidate(addr uint16)
+	reload() // Memory has been rewritten as a whole, as by LoadROM or LoadState
+}
+
+func (mem *Memory) addWriteObserver(observer writeObserver) {
+	mem.writeObservers = append(mem.writeObservers, observer)
+}
+
+func (mem *Memory) removeWriteObserver(observer writeObserver) {
+	for i, o := range mem.writeObservers {
+		if o == observer {
+			mem.writeObservers = append(mem.writeObservers[:i], mem.writeObservers[i+1:]...)
+			break
+		}
+	}
+	if len(mem.writeObservers) == 0 {
+		mem.writeObservers = nil
+	}
 }
 
 func NewMemory() *Memory {
-	mem := new(Memory)
+	return newMemory(MemorySize, 0x000)
+}
+
+func newMemory(size int, fontAddr uint16) *Memory {
+	mem := &Memory{Memory: make([]uint8, size)}
 
-	// Load default char sprites into 'interpreter area' (0x000 - 0x1FF) of memory
+	// Load default char sprites, by default into the 'interpreter area' (0x000 - 0x1FF) of memory
 	for ci, char := range CharSprites {
 		for bi, _byte := range char {
-			mem.Set8(uint16(ci*5+bi), _byte)
+			mem.Set8(fontAddr+uint16(ci*5+bi), _byte)
 		}
 	}
 
@@ -29,6 +58,10 @@ func (mem *Memory) Get8(addr uint16) (uint8, error) {
 		return 0x0, fmt.Errorf("memory address out of bounds: %v, capacity %v", addr, len(mem.Memory))
 	}
 
+	if mem.hooks != nil {
+		return mem.runReadHooks(addr, mem.Memory[addr])
+	}
+
 	return mem.Memory[addr], nil
 }
 
@@ -37,6 +70,33 @@ func (mem *Memory) Get16(addr uint16) (uint16, error) {
 		return 0x0, fmt.Errorf("memory address out of bounds: %v, capacity %v", addr, len(mem.Memory))
 	}
 
+	if mem.hooks != nil {
+		hi, err := mem.runReadHooks(addr, mem.Memory[addr])
+		if err != nil {
+			return 0x0, err
+		}
+		lo, err := mem.runReadHooks(addr+1, mem.Memory[addr+1])
+		if err != nil {
+			return 0x0, err
+		}
+		return uint16(hi)<<8 | uint16(lo), nil
+	}
+
+	return uint16(mem.Memory[addr])<<8 | uint16(mem.Memory[addr+1]), nil
+}
+
+func (mem *Memory) Fetch16(addr uint16) (uint16, error) {
+	if addr > uint16(len(mem.Memory)-2) {
+		return 0x0, fmt.Errorf("memory address out of bounds: %v, capacity %v", addr, len(mem.Memory))
+	}
+
+	if mem.hooks != nil {
+		err := mem.runExecuteHooks(addr)
+		if err != nil {
+			return 0x0, err
+		}
+	}
+
 	return uint16(mem.Memory[addr])<<8 | uint16(mem.Memory[addr+1]), nil
 }
 
@@ -45,7 +105,15 @@ func (mem *Memory) Set8(addr uint16, val uint8) error {
 		return fmt.Errorf("memory address out of bounds: %v, capacity %v", addr, len(mem.Memory))
 	}
 
-	mem.Memory[addr] = val
+	if mem.hooks != nil {
+		var err error
+		val, err = mem.runWriteHooks(addr, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	mem.poke(addr, val)
 
 	return nil
 }
@@ -55,12 +123,47 @@ func (mem *Memory) Set16(addr uint16, val uint16) error {
 		return fmt.Errorf("memory address out of bounds: %v, capacity %v", addr, len(mem.Memory))
 	}
 
-	mem.Memory[addr] = uint8(val & 0xFF00 >> 8)
-	mem.Memory[addr+1] = uint8(val & 0x00FF)
+	hi, lo := uint8(val&0xFF00>>8), uint8(val&0x00FF)
+
+	if mem.hooks != nil {
+		var err error
+		hi, err = mem.runWriteHooks(addr, hi)
+		if err != nil {
+			return err
+		}
+		lo, err = mem.runWriteHooks(addr+1, lo)
+		if err != nil {
+			return err
+		}
+	}
+
+	mem.Memory[addr] = hi
+	mem.Memory[addr+1] = lo
+
+	for _, observer := range mem.writeObservers {
+		observer.invalidate(addr)
+		observer.invalidate(addr + 1)
+	}
 
 	return nil
 }
 
+// Tells observers that Memory was rewritten directly, so anything derived from it is stale
+func (mem *Memory) reloaded() {
+	for _, observer := range mem.writeObservers {
+		observer.reload()
+	}
+}
+
+// Writes val without running write hooks, for devices that share memory with the Cpu
+func (mem *Memory) poke(addr uint16, val uint8) {
+	mem.Memory[addr] = val
+
+	for _, observer := range mem.writeObservers {
+		observer.invalidate(addr)
+	}
+}
+
 func (mem *Memory) GetPrettyMemoryState() string {
 	const columns = 16
 
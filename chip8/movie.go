@@ -0,0 +1,318 @@
+package chip8
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const movieMagic = "C8MV"
+const movieVersion = 4
+const DefaultCheckpointInterval = 60
+
+// Frames are read this many at a time, so a corrupt frame count runs out of input rather than allocating for it
+const movieReadChunk = 4096
+
+// Movie holds the per-frame keypad input of a recorded run, and the settings it ran under. Replaying it from
+// the same ROM under those settings is deterministic, as nothing else feeds the machine. Checkpoints map frame
+// numbers to the StateHash at the end of that frame.
+type Movie struct {
+	Settings           RunSettings
+	CheckpointInterval uint32
+	Keys               [][PadCount]uint16 // State of each keypad held during each frame
+	Checkpoints        map[uint32]uint64
+}
+
+// Replay with a Scheduler from NewSchedulerWithSettings(settings)
+func NewMovie(settings RunSettings) *Movie {
+	return &Movie{
+		Settings:           settings,
+		CheckpointInterval: DefaultCheckpointInterval,
+		Checkpoints:        make(map[uint32]uint64),
+	}
+}
+
+// Records the frame that has just run. Call once at the end of every frame.
+func (movie *Movie) RecordFrame(cpu *Cpu) {
+	frame := uint32(len(movie.Keys))
+	movie.Keys = append(movie.Keys, cpu.Keypad.Pads())
+
+	if movie.CheckpointInterval > 0 && frame%movie.CheckpointInterval == 0 {
+		movie.Checkpoints[frame] = cpu.StateHash()
+	}
+}
+
+// Records the keys of a frame that failed part way through, so a replay reaches the failure. Unlike RecordFrame,
+// it adds no checkpoint, as the state is not that of a finished frame.
+func (movie *Movie) RecordPartial(cpu *Cpu) {
+	movie.Keys = append(movie.Keys, cpu.Keypad.Pads())
+}
+
+// A Movie subscribed to a Scheduler records every frame it runs
+func (movie *Movie) BeginFrame(cpu *Cpu) error {
+	return nil
+}
+
+func (movie *Movie) EndFrame(cpu *Cpu) error {
+	movie.RecordFrame(cpu)
+	return nil
+}
+
+// Fixed-size part of the settings in a movie header, followed by the platform name
+type movieSettings struct {
+	MemorySize            uint32
+	Origin                uint16
+	FontAddr              uint16
+	StackDepth            uint16
+	MemoryStack           bool
+	StackTop              uint16
+	MemoryDisplay         bool
+	DisplayBase           uint16
+	DisplayWidth          uint16
+	DisplayHeight         uint16
+	Variant               uint8
+	Seed                  uint64
+	InstructionsPerFrame  uint32
+	CyclesPerFrame        uint64
+	DisplayWait           bool
+	VIPTiming             bool
+	TimingCyclesPerFrame  uint64
+	TimingInterruptCycles uint64
+}
+
+func newMovieSettings(settings RunSettings) movieSettings {
+	platform := settings.Platform
+	fields := movieSettings{
+		MemorySize:           uint32(platform.MemorySize),
+		Origin:               platform.Origin,
+		FontAddr:             platform.FontAddr,
+		StackDepth:           uint16(platform.StackDepth),
+		MemoryStack:          platform.MemoryStack,
+		StackTop:             platform.StackTop,
+		MemoryDisplay:        platform.MemoryDisplay,
+		DisplayBase:          platform.DisplayBase,
+		DisplayWidth:         uint16(platform.DisplayWidth),
+		DisplayHeight:        uint16(platform.DisplayHeight),
+		Variant:              uint8(platform.Variant),
+		Seed:                 settings.Seed,
+		InstructionsPerFrame: uint32(settings.InstructionsPerFrame),
+		CyclesPerFrame:       settings.CyclesPerFrame,
+		DisplayWait:          settings.DisplayWait,
+	}
+
+	if settings.Timing != nil {
+		fields.VIPTiming = true
+		fields.TimingCyclesPerFrame = settings.Timing.CyclesPerFrame
+		fields.TimingInterruptCycles = settings.Timing.InterruptCycles
+	}
+
+	return fields
+}
+
+func (fields movieSettings) settings(name string) RunSettings {
+	settings := RunSettings{
+		Platform: Platform{
+			Name:          name,
+			MemorySize:    int(fields.MemorySize),
+			Origin:        fields.Origin,
+			FontAddr:      fields.FontAddr,
+			StackDepth:    int(fields.StackDepth),
+			MemoryStack:   fields.MemoryStack,
+			StackTop:      fields.StackTop,
+			MemoryDisplay: fields.MemoryDisplay,
+			DisplayBase:   fields.DisplayBase,
+			DisplayWidth:  uint(fields.DisplayWidth),
+			DisplayHeight: uint(fields.DisplayHeight),
+			Variant:       Variant(fields.Variant),
+		},
+		Seed:                 fields.Seed,
+		InstructionsPerFrame: int(fields.InstructionsPerFrame),
+		CyclesPerFrame:       fields.CyclesPerFrame,
+		DisplayWait:          fields.DisplayWait,
+	}
+
+	if fields.VIPTiming {
+		settings.Timing = &VIPTiming{CyclesPerFrame: fields.TimingCyclesPerFrame, InterruptCycles: fields.TimingInterruptCycles}
+	}
+
+	return settings
+}
+
+// File layout, big-endian: magic, version, settings, platform name length and name, checkpoint interval,
+// frame count, each frame's keys for every pad, checkpoint count, then (frame, hash) pairs in frame order
+func (movie *Movie) Write(w io.Writer) error {
+	name := movie.Settings.Platform.Name
+	if len(name) > 0xFF {
+		return fmt.Errorf("platform name too long for a movie: %v bytes", len(name))
+	}
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(movieMagic)
+	bw.WriteByte(movieVersion)
+	binary.Write(bw, binary.BigEndian, newMovieSettings(movie.Settings))
+	bw.WriteByte(uint8(len(name)))
+	bw.WriteString(name)
+
+	var buf []byte
+	buf = binary.BigEndian.AppendUint32(buf, movie.CheckpointInterval)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(movie.Keys)))
+	for _, pads := range movie.Keys {
+		for _, keys := range pads {
+			buf = binary.BigEndian.AppendUint16(buf, keys)
+		}
+	}
+
+	// Checkpoints past the last frame are left out, as there is nothing to check them against
+	var checkpoints []byte
+	count := uint32(0)
+	for frame := range uint32(len(movie.Keys)) {
+		if hash, ok := movie.Checkpoints[frame]; ok {
+			checkpoints = binary.BigEndian.AppendUint32(checkpoints, frame)
+			checkpoints = binary.BigEndian.AppendUint64(checkpoints, hash)
+			count++
+		}
+	}
+	buf = binary.BigEndian.AppendUint32(buf, count)
+	buf = append(buf, checkpoints...)
+	bw.Write(buf)
+
+	return bw.Flush()
+}
+
+func ReadMovie(r io.Reader) (*Movie, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(movieMagic)+1)
+	_, err := io.ReadFull(br, header)
+	if err != nil {
+		return nil, fmt.Errorf("reading movie header: %w", err)
+	}
+	if string(header[:len(movieMagic)]) != movieMagic {
+		return nil, fmt.Errorf("not a movie file: bad magic %q", header[:len(movieMagic)])
+	}
+	if header[len(movieMagic)] != movieVersion {
+		return nil, fmt.Errorf("unsupported movie version: %v", header[len(movieMagic)])
+	}
+
+	var fields movieSettings
+	err = binary.Read(br, binary.BigEndian, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("reading movie settings: %w", err)
+	}
+
+	nameLength, err := br.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading movie settings: %w", err)
+	}
+	name := make([]byte, nameLength)
+	_, err = io.ReadFull(br, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading movie settings: %w", err)
+	}
+
+	movie := NewMovie(fields.settings(string(name)))
+	err = movie.Settings.Platform.validate()
+	if err != nil {
+		return nil, fmt.Errorf("movie platform: %w", err)
+	}
+
+	var frames uint32
+	err = binary.Read(br, binary.BigEndian, &movie.CheckpointInterval)
+	if err == nil {
+		err = binary.Read(br, binary.BigEndian, &frames)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading movie header: %w", err)
+	}
+
+	for remaining := frames; remaining > 0; {
+		chunk := make([][PadCount]uint16, min(remaining, movieReadChunk))
+		err = binary.Read(br, binary.BigEndian, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("reading movie frames: %w", err)
+		}
+		movie.Keys = append(movie.Keys, chunk...)
+		remaining -= uint32(len(chunk))
+	}
+
+	var checkpoints uint32
+	err = binary.Read(br, binary.BigEndian, &checkpoints)
+	if err != nil {
+		return nil, fmt.Errorf("reading movie checkpoints: %w", err)
+	}
+
+	for range checkpoints {
+		var checkpoint struct {
+			Frame uint32
+			Hash  uint64
+		}
+		err = binary.Read(br, binary.BigEndian, &checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("reading movie checkpoints: %w", err)
+		}
+		if checkpoint.Frame >= frames {
+			return nil, fmt.Errorf("movie checkpoint for frame %v beyond end of movie (%v frames)", checkpoint.Frame, frames)
+		}
+		movie.Checkpoints[checkpoint.Frame] = checkpoint.Hash
+	}
+
+	return movie, nil
+}
+
+type DesyncError struct {
+	Frame    uint32
+	WantHash uint64
+	GotHash  uint64
+}
+
+func (err *DesyncError) Error() string {
+	return fmt.Sprintf("replay desync at frame %v: state hash %016X, recorded %016X", err.Frame, err.GotHash, err.WantHash)
+}
+
+// MoviePlayer feeds a movie's input back into a Cpu and checks its checkpoints.
+// Subscribe it to a Scheduler, or call BeginFrame before running each frame and EndFrame after it.
+type MoviePlayer struct {
+	movie *Movie
+	frame uint32
+}
+
+func NewMoviePlayer(movie *Movie) *MoviePlayer {
+	return &MoviePlayer{movie: movie}
+}
+
+func (player *MoviePlayer) Done() bool {
+	return player.frame >= uint32(len(player.movie.Keys))
+}
+
+func (player *MoviePlayer) Frame() uint32 {
+	return player.frame
+}
+
+func (player *MoviePlayer) BeginFrame(cpu *Cpu) error {
+	if player.Done() {
+		return fmt.Errorf("movie finished after %v frames", len(player.movie.Keys))
+	}
+
+	cpu.Keypad.SetPads(player.movie.Keys[player.frame])
+
+	return nil
+}
+
+// Returns a *DesyncError if the frame just run has a checkpoint that does not match
+func (player *MoviePlayer) EndFrame(cpu *Cpu) error {
+	frame := player.frame
+	player.frame++
+
+	want, ok := player.movie.Checkpoints[frame]
+	if !ok {
+		return nil
+	}
+
+	if got := cpu.StateHash(); got != want {
+		return &DesyncError{Frame: frame, WantHash: want, GotHash: got}
+	}
+
+	return nil
+}
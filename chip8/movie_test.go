@@ -0,0 +1,223 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+const movieTestTicksPerFrame = 10
+
+func newMovieTestCpu() *Cpu {
+	cpu := NewCpu()
+
+	// 0x200: ADD V0, 0x01 / ADD V1, 0x03 / XOR V2, V0 / JP 0x200
+	for i, opcode := range []uint16{0x7001, 0x7103, 0x8203, 0x1200} {
+		cpu.Memory.Set16(uint16(0x200+i*2), opcode)
+	}
+
+	return cpu
+}
+
+func runMovieTestFrame(t *testing.T, cpu *Cpu) {
+	for range movieTestTicksPerFrame {
+		err := cpu.Tick()
+		if err != nil {
+			t.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	}
+}
+
+func recordMovieTest(t *testing.T, frames int) *Movie {
+	cpu := newMovieTestCpu()
+	movie := NewMovie(RunSettings{Platform: PlatformChip8, InstructionsPerFrame: movieTestTicksPerFrame})
+	movie.CheckpointInterval = 10
+
+	for range frames {
+		cpu.Keypad.SetPads([PadCount]uint16{uint16(rand.Intn(0x10000)), uint16(rand.Intn(0x10000))})
+		runMovieTestFrame(t, cpu)
+		movie.RecordFrame(cpu)
+	}
+
+	return movie
+}
+
+func TestMovie_WriteRead(t *testing.T) {
+	want := recordMovieTest(t, 95)
+	want.Settings = RunSettings{
+		Platform:             Platform{Name: "custom", MemorySize: 0x2000, Origin: 0x600, StackDepth: 8, MemoryStack: true, StackTop: 0x1000, DisplayWidth: 128, DisplayHeight: 64, Variant: VariantChip8E},
+		Seed:                 0xDEADBEEF,
+		InstructionsPerFrame: 20,
+		CyclesPerFrame:       3000,
+		DisplayWait:          true,
+		Timing:               &VIPTiming{CyclesPerFrame: 3000, InterruptCycles: 100},
+	}
+
+	var buf bytes.Buffer
+	err := want.Write(&buf)
+	if err != nil {
+		t.Fatalf("Movie.Write() error = %v", err)
+	}
+
+	got, err := ReadMovie(&buf)
+	if err != nil {
+		t.Fatalf("ReadMovie() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadMovie() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMovie_WriteCheckpointPastEnd(t *testing.T) {
+	movie := recordMovieTest(t, 15)
+	movie.Checkpoints[15] = 0x1234
+	movie.Checkpoints[100] = 0x5678
+
+	var buf bytes.Buffer
+	err := movie.Write(&buf)
+	if err != nil {
+		t.Fatalf("Movie.Write() error = %v", err)
+	}
+
+	got, err := ReadMovie(&buf)
+	if err != nil {
+		t.Fatalf("ReadMovie() error = %v", err)
+	}
+	if want := map[uint32]uint64{0: movie.Checkpoints[0], 10: movie.Checkpoints[10]}; !reflect.DeepEqual(got.Checkpoints, want) {
+		t.Errorf("ReadMovie() checkpoints = %v, want %v", got.Checkpoints, want)
+	}
+}
+
+func TestReadMovie_Invalid(t *testing.T) {
+	var empty bytes.Buffer
+	NewMovie(RunSettings{Platform: PlatformChip8}).Write(&empty)
+	header := empty.Bytes()[:empty.Len()-8] // Up to and including the checkpoint interval
+
+	var badPlatform bytes.Buffer
+	NewMovie(RunSettings{Platform: Platform{Name: "broken"}}).Write(&badPlatform)
+
+	tests := map[string][]byte{
+		"empty":            {},
+		"bad magic":        []byte("XXXX\x01"),
+		"bad version":      []byte("C8MV\x09"),
+		"old version":      []byte("C8MV\x03"),
+		"truncated":        []byte("C8MV\x04\x00\x00"),
+		"truncated name":   empty.Bytes()[:len(header)-5],
+		"bad platform":     badPlatform.Bytes(),
+		"huge frame count": append(bytes.Clone(header), 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x01),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadMovie(bytes.NewReader(data))
+			if err == nil {
+				t.Errorf("ReadMovie() did not throw error as wanted")
+			}
+		})
+	}
+}
+
+func TestMoviePlayer_Replay(t *testing.T) {
+	movie := recordMovieTest(t, 100)
+
+	cpu := newMovieTestCpu()
+	player := NewMoviePlayer(movie)
+
+	for frame := 0; !player.Done(); frame++ {
+		err := player.BeginFrame(cpu)
+		if err != nil {
+			t.Fatalf("MoviePlayer.BeginFrame() error = %v", err)
+		}
+
+		if cpu.Keypad.Pads() != movie.Keys[frame] {
+			t.Fatalf("MoviePlayer.BeginFrame() set keys %04X, want %04X", cpu.Keypad.Pads(), movie.Keys[frame])
+		}
+
+		runMovieTestFrame(t, cpu)
+
+		err = player.EndFrame(cpu)
+		if err != nil {
+			t.Fatalf("MoviePlayer.EndFrame() error = %v", err)
+		}
+	}
+
+	if player.BeginFrame(cpu) == nil {
+		t.Errorf("MoviePlayer.BeginFrame() past end did not throw error as wanted")
+	}
+}
+
+// The second keypad feeds CHIP-8X's SKP2, so a replay without it desyncs
+func TestMoviePlayer_SecondPad(t *testing.T) {
+	newCpu := func() *Cpu {
+		cpu, _ := NewCpuWithPlatform(PlatformChip8X)
+		cpu.LoadROM([]byte{0xE0, 0xF2, 0x71, 0x01, 0x70, 0x01, 0x13, 0x00}) // SKP2 V0 / ADD V1, 0x01 / ADD V0, 0x01 / JP 0x300
+		return cpu
+	}
+
+	recorder := newCpu()
+	movie := NewMovie(RunSettings{Platform: PlatformChip8X, InstructionsPerFrame: movieTestTicksPerFrame})
+	movie.CheckpointInterval = 10
+	for range 50 {
+		recorder.Keypad.PressPad(1, uint8(rand.Intn(KeyCount)))
+		runMovieTestFrame(t, recorder)
+		movie.RecordFrame(recorder)
+	}
+
+	var buf bytes.Buffer
+	movie.Write(&buf)
+	movie, err := ReadMovie(&buf)
+	if err != nil {
+		t.Fatalf("ReadMovie() error = %v", err)
+	}
+
+	cpu := newCpu()
+	player := NewMoviePlayer(movie)
+	for !player.Done() {
+		player.BeginFrame(cpu)
+		runMovieTestFrame(t, cpu)
+		err := player.EndFrame(cpu)
+		if err != nil {
+			t.Fatalf("MoviePlayer.EndFrame() error = %v", err)
+		}
+	}
+
+	if cpu.V[1] != recorder.V[1] || cpu.Keypad.Pads() != recorder.Keypad.Pads() {
+		t.Errorf("replay left V1 = %v, pads %04X, want %v and %04X", cpu.V[1], cpu.Keypad.Pads(), recorder.V[1], recorder.Keypad.Pads())
+	}
+}
+
+func TestMoviePlayer_Desync(t *testing.T) {
+	const divergeFrame = 33
+
+	movie := recordMovieTest(t, 100)
+
+	cpu := newMovieTestCpu()
+	player := NewMoviePlayer(movie)
+
+	for frame := 0; !player.Done(); frame++ {
+		player.BeginFrame(cpu)
+		runMovieTestFrame(t, cpu)
+
+		if frame == divergeFrame {
+			cpu.V[0x5]++
+		}
+
+		err := player.EndFrame(cpu)
+		if err == nil {
+			continue
+		}
+
+		var desync *DesyncError
+		if !errors.As(err, &desync) {
+			t.Fatalf("MoviePlayer.EndFrame() error = %v, want DesyncError", err)
+		}
+		if desync.Frame != 40 {
+			t.Errorf("MoviePlayer.EndFrame() desync at frame %v, want first checkpoint after divergence (40)", desync.Frame)
+		}
+		return
+	}
+
+	t.Errorf("MoviePlayer did not detect divergence")
+}
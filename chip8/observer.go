@@ -0,0 +1,193 @@
+package chip8
+
+// Observer is notified of what the Cpu does. Any of its functions may be nil. With no observers added the
+// Cpu pays a single nil check per instruction, and the unset functions of added ones are skipped.
+type Observer struct {
+	BeforeExecute func(cpu *Cpu, inst Instruction) // PC still holds the instruction's address
+	AfterExecute  func(cpu *Cpu, inst Instruction, delta Delta)
+	Clear         func(cpu *Cpu)
+	Draw          func(cpu *Cpu, x uint8, y uint8, rows uint8, collision bool) // x and y are the sprite's origin in pixels
+	Timer         func(cpu *Cpu, timer Timer, val uint8)
+	KeyWait       func(cpu *Cpu, x uint8) // LD Vx, K found no key held, and will run again
+}
+
+type ObserverID int
+
+type Timer int
+
+const (
+	TimerDelay Timer = iota
+	TimerSound
+)
+
+func (timer Timer) String() string {
+	if timer == TimerSound {
+		return "ST"
+	}
+	return "DT"
+}
+
+// Registers is the Cpu's register file, as captured around an instruction for observers
+type Registers struct {
+	V  [0x10]uint8
+	I  uint16
+	PC uint16
+	SP uint8
+	DT uint8
+	ST uint8
+}
+
+// Delta is the change an instruction made to the registers. Memory changes are left to memory hooks.
+type Delta struct {
+	Before Registers
+	After  Registers
+}
+
+// Bit n set when Vn changed
+func (delta Delta) ChangedV() uint16 {
+	var changed uint16
+	for i := range delta.Before.V {
+		if delta.Before.V[i] != delta.After.V[i] {
+			changed |= 1 << i
+		}
+	}
+	return changed
+}
+
+type observers struct {
+	nextID  ObserverID
+	entries []observerEntry
+}
+
+type observerEntry struct {
+	id       ObserverID
+	observer Observer
+}
+
+func (cpu *Cpu) AddObserver(observer Observer) ObserverID {
+	if cpu.observers == nil {
+		cpu.observers = new(observers)
+	}
+
+	cpu.observers.nextID++
+	cpu.observers.entries = append(cpu.observers.entries, observerEntry{cpu.observers.nextID, observer})
+	return cpu.observers.nextID
+}
+
+func (cpu *Cpu) RemoveObserver(id ObserverID) {
+	if cpu.observers == nil {
+		return
+	}
+
+	// Observers may remove themselves from a callback, so the slice being notified is left as it is
+	entries := make([]observerEntry, 0, len(cpu.observers.entries))
+	for _, entry := range cpu.observers.entries {
+		if entry.id != id {
+			entries = append(entries, entry)
+		}
+	}
+	cpu.observers.entries = entries
+
+	if len(cpu.observers.entries) == 0 {
+		cpu.observers = nil
+	}
+}
+
+// Whether any observers are added - faster execution paths fall back to Tick when there are
+func (cpu *Cpu) HasObservers() bool {
+	return cpu.observers != nil
+}
+
+func (cpu *Cpu) registers() Registers {
+	return Registers{V: cpu.V, I: cpu.I, PC: cpu.PC, SP: cpu.SP, DT: cpu.DT, ST: cpu.ST}
+}
+
+// Tick's execute step with observers notified either side
+func (cpu *Cpu) executeObserved(inst Instruction) error {
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.BeforeExecute != nil {
+			entry.observer.BeforeExecute(cpu, inst)
+		}
+	}
+
+	before := cpu.registers()
+	cpu.PC += 2
+
+	err := execute(inst, cpu)
+	if err != nil {
+		return err
+	}
+
+	// Observers may have removed themselves, so check again
+	if cpu.observers == nil {
+		return nil
+	}
+
+	delta := Delta{Before: before, After: cpu.registers()}
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.AfterExecute != nil {
+			entry.observer.AfterExecute(cpu, inst, delta)
+		}
+	}
+
+	return nil
+}
+
+func (cpu *Cpu) notifyClear() {
+	if cpu.observers == nil {
+		return
+	}
+
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.Clear != nil {
+			entry.observer.Clear(cpu)
+		}
+	}
+}
+
+func (cpu *Cpu) notifyDraw(x uint8, y uint8, rows uint8, collision bool) {
+	if cpu.observers == nil {
+		return
+	}
+
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.Draw != nil {
+			entry.observer.Draw(cpu, x, y, rows, collision)
+		}
+	}
+}
+
+func (cpu *Cpu) notifyKeyWait(x uint8) {
+	if cpu.observers == nil {
+		return
+	}
+
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.KeyWait != nil {
+			entry.observer.KeyWait(cpu, x)
+		}
+	}
+}
+
+// Sets DT or ST, notifying observers if it changed
+func (cpu *Cpu) setTimer(timer Timer, val uint8) {
+	reg := &cpu.DT
+	if timer == TimerSound {
+		reg = &cpu.ST
+	}
+
+	if *reg == val {
+		return
+	}
+	*reg = val
+
+	if cpu.observers == nil {
+		return
+	}
+
+	for _, entry := range cpu.observers.entries {
+		if entry.observer.Timer != nil {
+			entry.observer.Timer(cpu, timer, val)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package chip8
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestObserver_Execute(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x05, 0x81, 0x04}) // LD V0, 0x05 / ADD V1, V0
+
+	var events []string
+	var deltas []Delta
+	id := cpu.AddObserver(Observer{
+		BeforeExecute: func(cpu *Cpu, inst Instruction) {
+			events = append(events, fmt.Sprintf("before %04X at %03X", inst.Opcode, cpu.PC))
+		},
+		AfterExecute: func(cpu *Cpu, inst Instruction, delta Delta) {
+			events = append(events, fmt.Sprintf("after %04X", inst.Opcode))
+			deltas = append(deltas, delta)
+		},
+	})
+
+	if !cpu.HasObservers() {
+		t.Fatalf("Cpu.HasObservers() = false after AddObserver")
+	}
+
+	cpu.Tick()
+	cpu.Tick()
+
+	want := []string{"before 6005 at 200", "after 6005", "before 8104 at 202", "after 8104"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("observer saw %v, want %v", events, want)
+	}
+
+	if got := deltas[0].ChangedV(); got != 0x0001 {
+		t.Errorf("Delta.ChangedV() = %04X for LD V0, want 0001", got)
+	}
+	if got := deltas[1].ChangedV(); got != 0x0002 {
+		t.Errorf("Delta.ChangedV() = %04X for ADD V1, V0 with no carry, want 0002", got)
+	}
+	if deltas[1].Before.PC != 0x202 || deltas[1].After.PC != 0x204 {
+		t.Errorf("Delta PC = %03X -> %03X, want 202 -> 204", deltas[1].Before.PC, deltas[1].After.PC)
+	}
+
+	cpu.RemoveObserver(id)
+	if cpu.HasObservers() {
+		t.Errorf("Cpu.HasObservers() = true after removing the only observer")
+	}
+
+	cpu.PC = 0x200
+	cpu.Tick()
+	if len(events) != 4 {
+		t.Errorf("removed observer still called: %v", events)
+	}
+}
+
+func TestObserver_RemoveSelf(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x05, 0x60, 0x06}) // LD V0, 0x05 / LD V0, 0x06
+
+	var id ObserverID
+	id = cpu.AddObserver(Observer{
+		BeforeExecute: func(cpu *Cpu, inst Instruction) { cpu.RemoveObserver(id) },
+	})
+
+	calls := make([]int, 2)
+	for i := range calls {
+		cpu.AddObserver(Observer{
+			BeforeExecute: func(cpu *Cpu, inst Instruction) { calls[i]++ },
+		})
+	}
+
+	cpu.Tick()
+	cpu.Tick()
+	if want := []int{2, 2}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("observers after a self-removing one called %v times in 2 ticks, want %v", calls, want)
+	}
+}
+
+func TestObserver_Events(t *testing.T) {
+	tests := map[string]struct {
+		rom  []byte
+		keys uint16
+		want []string
+	}{
+		"clear": {
+			rom:  []byte{0x00, 0xE0},
+			want: []string{"clear"},
+		},
+		"draw": {
+			rom:  []byte{0x60, 0x08, 0x61, 0x03, 0xA0, 0x00, 0xD0, 0x15, 0xD0, 0x11}, // I = font "0", draw it twice
+			want: []string{"draw 8,3 5 rows false", "draw 8,3 1 rows true"},
+		},
+		"timers": {
+			rom:  []byte{0x60, 0x03, 0xF0, 0x15, 0xF0, 0x18, 0xF0, 0x15},
+			want: []string{"DT 3", "ST 3"},
+		},
+		"key wait": {
+			rom:  []byte{0xF5, 0x0A},
+			want: []string{"key wait V5", "key wait V5"},
+		},
+		"key held": {
+			rom:  []byte{0xF5, 0x0A},
+			keys: 0x0001,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			cpu.LoadROM(test.rom)
+			cpu.Keypad.SetState(test.keys)
+
+			var got []string
+			cpu.AddObserver(Observer{
+				Clear: func(cpu *Cpu) {
+					got = append(got, "clear")
+				},
+				Draw: func(cpu *Cpu, x uint8, y uint8, rows uint8, collision bool) {
+					got = append(got, fmt.Sprintf("draw %v,%v %v rows %v", x, y, rows, collision))
+				},
+				Timer: func(cpu *Cpu, timer Timer, val uint8) {
+					got = append(got, fmt.Sprintf("%v %v", timer, val))
+				},
+				KeyWait: func(cpu *Cpu, x uint8) {
+					got = append(got, fmt.Sprintf("key wait V%X", x))
+				},
+			})
+
+			for range max(len(test.rom)/2, 2) {
+				err := cpu.Tick()
+				if err != nil {
+					t.Fatalf("Cpu.Tick() error = %v", err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("observer saw %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestObserver_SchedulerTimers(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	cpu.DT, cpu.ST = 2, 1
+
+	var got []string
+	cpu.AddObserver(Observer{
+		Timer: func(cpu *Cpu, timer Timer, val uint8) {
+			got = append(got, fmt.Sprintf("%v %v", timer, val))
+		},
+	})
+	NewScheduler(cpu).RunFrames(3)
+
+	if want := []string{"DT 1", "ST 0", "DT 0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("observer saw %v, want %v", got, want)
+	}
+}
+
+// Observers run on every instruction, so must not make Tick allocate
+func TestObserver_Allocations(t *testing.T) {
+	cpu := newBenchmarkCpu()
+	cpu.AddObserver(Observer{
+		BeforeExecute: func(cpu *Cpu, inst Instruction) {},
+		AfterExecute:  func(cpu *Cpu, inst Instruction, delta Delta) {},
+	})
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		err := cpu.Tick()
+		if err != nil {
+			t.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Cpu.Tick() with observers allocates %v times per instruction, want 0", allocs)
+	}
+}
+
+func BenchmarkTickObserved(b *testing.B) {
+	cpu := newBenchmarkCpu()
+	cpu.AddObserver(Observer{
+		AfterExecute: func(cpu *Cpu, inst Instruction, delta Delta) {},
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		err := cpu.Tick()
+		if err != nil {
+			b.Fatalf("Cpu.Tick() error = %v", err)
+		}
+	}
+
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "instructions/s")
+}
+
+func TestObserver_BlockEngine(t *testing.T) {
+	cpu := newBenchmarkCpu()
+	engine := NewBlockEngine(cpu)
+	defer engine.Close()
+
+	var count int
+	cpu.AddObserver(Observer{
+		BeforeExecute: func(cpu *Cpu, inst Instruction) { count++ },
+	})
+
+	executed, err := engine.Run(50)
+	if err != nil {
+		t.Fatalf("BlockEngine.Run() error = %v", err)
+	}
+	if count != executed {
+		t.Errorf("observer saw %v instructions, BlockEngine ran %v", count, executed)
+	}
+}
@@ -0,0 +1,125 @@
+package chip8
+
+import "fmt"
+
+// Bytes taken by the built-in hex digit sprites
+const fontSize = len(CharSprites) * len(CharSprites[0])
+
+// Memory layout of a target machine
+type Platform struct {
+	Name       string
+	MemorySize int    // Bytes of RAM, up to 64K
+	Origin     uint16 // Address ROMs are loaded at, and where execution starts
+	FontAddr   uint16 // Address of the built-in hex digit sprites
+	StackDepth int    // Return addresses the stack can hold
+
+	MemoryStack bool   // Keep the stack in Memory, where programs can inspect or clobber it, rather than in Cpu.Stack
+	StackTop    uint16 // Address just above the memory-backed stack, which grows down from it
+
+	MemoryDisplay bool   // Keep the framebuffer in Memory, where programs can read or write pixels directly
+	DisplayBase   uint16 // Address of the memory-mapped framebuffer
+
+	DisplayWidth  uint // Pixels, a multiple of 8 up to 128 - 0 for the standard 64
+	DisplayHeight uint // Pixels, up to 64 - 0 for the standard 32
+
+	Variant Variant // Instruction set variant, added to the Cpu as extensions
+}
+
+// Variant selects an instruction set that adds to or replaces some of the standard CHIP-8 opcodes
+type Variant int
+
+const (
+	VariantNone Variant = iota
+	VariantChip8X
+	VariantChip8E
+)
+
+func (variant Variant) String() string {
+	switch variant {
+	case VariantNone:
+		return "none"
+	case VariantChip8X:
+		return "CHIP-8X"
+	case VariantChip8E:
+		return "CHIP-8E"
+	}
+	return fmt.Sprintf("Variant(%d)", int(variant))
+}
+
+// Extensions implementing the variant's opcodes
+func (variant Variant) extensions() []Extension {
+	switch variant {
+	case VariantChip8X:
+		return chip8XExtensions
+	case VariantChip8E:
+		return chip8EExtensions
+	}
+	return nil
+}
+
+var (
+	PlatformChip8  = Platform{Name: "CHIP-8", MemorySize: MemorySize, Origin: 0x200, FontAddr: 0x000, StackDepth: StackSize}
+	PlatformVIP    = Platform{Name: "COSMAC VIP", MemorySize: 0x1000, Origin: 0x200, FontAddr: 0x000, StackDepth: 12, MemoryStack: true, StackTop: 0xED0, MemoryDisplay: true, DisplayBase: 0xF00}
+	PlatformVIP2K  = Platform{Name: "COSMAC VIP 2K", MemorySize: 0x800, Origin: 0x200, FontAddr: 0x000, StackDepth: 12, MemoryStack: true, StackTop: 0x6D0, MemoryDisplay: true, DisplayBase: 0x700}
+	PlatformETI660 = Platform{Name: "ETI-660", MemorySize: 0x1000, Origin: 0x600, FontAddr: 0x000, StackDepth: StackSize}
+	PlatformModern = Platform{Name: "CHIP-8 (modern)", MemorySize: 0x1000, Origin: 0x200, FontAddr: 0x050, StackDepth: StackSize}
+	PlatformXOChip = Platform{Name: "XO-CHIP", MemorySize: 0x10000, Origin: 0x200, FontAddr: 0x000, StackDepth: StackSize}
+	PlatformChip8X = Platform{Name: "CHIP-8X", MemorySize: 0x1000, Origin: 0x300, FontAddr: 0x000, StackDepth: 12, MemoryStack: true, StackTop: 0xED0, MemoryDisplay: true, DisplayBase: 0xF00, Variant: VariantChip8X}
+	PlatformChip8E = Platform{Name: "CHIP-8E", MemorySize: 0x1000, Origin: 0x200, FontAddr: 0x000, StackDepth: 12, MemoryStack: true, StackTop: 0xED0, MemoryDisplay: true, DisplayBase: 0xF00, Variant: VariantChip8E}
+	PlatformChip10 = Platform{Name: "CHIP-10", MemorySize: 0x1000, Origin: 0x200, FontAddr: 0x000, StackDepth: 12, MemoryStack: true, StackTop: 0xBD0, MemoryDisplay: true, DisplayBase: 0xC00, DisplayWidth: 128, DisplayHeight: 64}
+)
+
+var Platforms = []Platform{PlatformChip8, PlatformVIP, PlatformVIP2K, PlatformETI660, PlatformModern, PlatformXOChip, PlatformChip8X, PlatformChip8E, PlatformChip10}
+
+func PlatformByName(name string) (Platform, error) {
+	for _, platform := range Platforms {
+		if platform.Name == name {
+			return platform, nil
+		}
+	}
+	return Platform{}, fmt.Errorf("unknown platform: %q", name)
+}
+
+func (platform Platform) displaySize() (uint, uint) {
+	w, h := platform.DisplayWidth, platform.DisplayHeight
+	if w == 0 {
+		w = width
+	}
+	if h == 0 {
+		h = height
+	}
+	return w, h
+}
+
+func (platform Platform) validate() error {
+	if platform.MemorySize < 0x200 || platform.MemorySize > 0x10000 {
+		return fmt.Errorf("invalid memory size for %v: %v, want 0x200 - 0x10000", platform.Name, platform.MemorySize)
+	}
+	if int(platform.Origin)+2 > platform.MemorySize {
+		return fmt.Errorf("load origin %04X outside %v bytes of memory", platform.Origin, platform.MemorySize)
+	}
+	if int(platform.FontAddr)+fontSize > platform.MemorySize {
+		return fmt.Errorf("font at %04X does not fit in %v bytes of memory", platform.FontAddr, platform.MemorySize)
+	}
+	if platform.StackDepth < 1 || platform.StackDepth > 0xFF {
+		return fmt.Errorf("invalid stack depth for %v: %v, want 1 - 255", platform.Name, platform.StackDepth)
+	}
+	if platform.MemoryStack && (int(platform.StackTop) > platform.MemorySize || int(platform.StackTop) < platform.StackDepth*2) {
+		return fmt.Errorf("stack below %04X does not fit in %v bytes of memory", platform.StackTop, platform.MemorySize)
+	}
+	w, h := platform.displaySize()
+	err := checkDisplaySize(w, h)
+	if err != nil {
+		return fmt.Errorf("%v: %w", platform.Name, err)
+	}
+	if platform.MemoryDisplay && int(platform.DisplayBase)+mappedDisplaySize(w, h) > platform.MemorySize {
+		return fmt.Errorf("display at %04X does not fit in %v bytes of memory", platform.DisplayBase, platform.MemorySize)
+	}
+	if platform.Variant == VariantChip8X && (w != width || h != height) {
+		return fmt.Errorf("%v colour zones need a %vx%v display", platform.Name, width, height)
+	}
+	if platform.Variant < VariantNone || platform.Variant > VariantChip8E {
+		return fmt.Errorf("unknown instruction set variant for %v: %v", platform.Name, platform.Variant)
+	}
+	return nil
+}
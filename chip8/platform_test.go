@@ -0,0 +1,148 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewCpuWithPlatform(t *testing.T) {
+	for _, platform := range Platforms {
+		t.Run(platform.Name, func(t *testing.T) {
+			cpu, err := NewCpuWithPlatform(platform)
+			if err != nil {
+				t.Fatalf("NewCpuWithPlatform() error = %v", err)
+			}
+
+			if len(cpu.Memory.Memory) != platform.MemorySize {
+				t.Errorf("memory size = %v, want %v", len(cpu.Memory.Memory), platform.MemorySize)
+			}
+			if len(cpu.StackEntries()) != platform.StackDepth {
+				t.Errorf("stack depth = %v, want %v", len(cpu.StackEntries()), platform.StackDepth)
+			}
+			if cpu.PC != platform.Origin {
+				t.Errorf("PC = %04X, want origin %04X", cpu.PC, platform.Origin)
+			}
+
+			font := cpu.Memory.Memory[platform.FontAddr : int(platform.FontAddr)+fontSize]
+			if !bytes.Equal(font[:5], CharSprites[0][:]) || !bytes.Equal(font[fontSize-5:], CharSprites[0xF][:]) {
+				t.Errorf("font not loaded at %04X", platform.FontAddr)
+			}
+		})
+	}
+}
+
+func TestNewCpuWithPlatform_Invalid(t *testing.T) {
+	tests := map[string]Platform{
+		"memory too small":       {Name: "small", MemorySize: 0x100, Origin: 0x000, StackDepth: 16},
+		"memory too large":       {Name: "large", MemorySize: 0x10001, Origin: 0x200, StackDepth: 16},
+		"origin outside memory":  {Name: "origin", MemorySize: 0x800, Origin: 0x800, StackDepth: 16},
+		"font outside memory":    {Name: "font", MemorySize: 0x800, Origin: 0x200, FontAddr: 0x7F0, StackDepth: 16},
+		"no stack":               {Name: "stack", MemorySize: 0x1000, Origin: 0x200},
+		"display outside memory": {Name: "display", MemorySize: 0x800, Origin: 0x200, StackDepth: 12, MemoryDisplay: true, DisplayBase: 0x780},
+		"stack outside memory":   {Name: "stack", MemorySize: 0x800, Origin: 0x200, StackDepth: 12, MemoryStack: true, StackTop: 0x802},
+		"stack below memory":     {Name: "stack", MemorySize: 0x800, Origin: 0x200, StackDepth: 12, MemoryStack: true, StackTop: 0x010},
+		"display too wide":       {Name: "wide", MemorySize: 0x1000, Origin: 0x200, StackDepth: 12, DisplayWidth: 136},
+		"hi-res display outside": {Name: "hires", MemorySize: 0x1000, Origin: 0x200, StackDepth: 12, MemoryDisplay: true, DisplayBase: 0xF00, DisplayWidth: 128, DisplayHeight: 64},
+		"colour zones hi-res":    {Name: "colour", MemorySize: 0x1000, Origin: 0x200, StackDepth: 12, DisplayWidth: 128, Variant: VariantChip8X},
+		"unknown variant":        {Name: "variant", MemorySize: 0x1000, Origin: 0x200, StackDepth: 12, Variant: -1},
+	}
+
+	for name, platform := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewCpuWithPlatform(platform)
+			if err == nil {
+				t.Errorf("NewCpuWithPlatform() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestPlatformByName(t *testing.T) {
+	platform, err := PlatformByName("ETI-660")
+	if err != nil || platform != PlatformETI660 {
+		t.Errorf("PlatformByName() = %v, %v, want ETI-660", platform, err)
+	}
+
+	_, err = PlatformByName("nope")
+	if err == nil {
+		t.Errorf("PlatformByName() error = nil for unknown platform")
+	}
+}
+
+func TestPlatform_LoadROM(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformETI660)
+
+	err := cpu.LoadROM([]byte{0x60, 0x42})
+	if err != nil {
+		t.Fatalf("Cpu.LoadROM() error = %v", err)
+	}
+
+	cpu.Tick()
+	if cpu.V[0] != 0x42 || cpu.PC != 0x602 {
+		t.Errorf("after one tick V0 = %02X, PC = %04X, want 42 and 0602", cpu.V[0], cpu.PC)
+	}
+
+	err = cpu.LoadROM(make([]byte, 0x1000-0x600+1))
+	if err == nil {
+		t.Errorf("Cpu.LoadROM() error = nil for ROM past end of memory")
+	}
+}
+
+func TestPlatform_StackDepth(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformVIP)
+	cpu.LoadROM([]byte{0x22, 0x00}) // CALL 0x200, forever
+
+	for i := range PlatformVIP.StackDepth {
+		if err := cpu.Tick(); err != nil {
+			t.Fatalf("Cpu.Tick() error = %v on call %v", err, i+1)
+		}
+	}
+
+	if err := cpu.Tick(); err == nil {
+		t.Errorf("Cpu.Tick() error = nil, want stack overflow after %v calls", PlatformVIP.StackDepth)
+	}
+}
+
+func TestPlatform_JumpOutsideMemory(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformVIP2K)
+	cpu.LoadROM([]byte{0x18, 0x00}) // JP 0x800
+
+	if err := cpu.Tick(); err == nil {
+		t.Errorf("Cpu.Tick() error = nil, want JP past 2K of memory to fail")
+	}
+}
+
+func TestPlatform_SaveState(t *testing.T) {
+	for _, platform := range Platforms {
+		t.Run(platform.Name, func(t *testing.T) {
+			want := getRandomPlatformCpuState(platform)
+
+			got, _ := NewCpuWithPlatform(platform)
+			err := got.LoadState(want.SaveState())
+			if err != nil {
+				t.Fatalf("Cpu.LoadState() error = %v", err)
+			}
+
+			if !bytes.Equal(got.SaveState(), want.SaveState()) {
+				t.Errorf("Cpu.LoadState() did not restore the saved state")
+			}
+		})
+	}
+
+	vip2k, _ := NewCpuWithPlatform(PlatformVIP2K)
+	if err := NewCpu().LoadState(vip2k.SaveState()); err == nil {
+		t.Errorf("Cpu.LoadState() error = nil for a state from a different layout")
+	}
+}
+
+func TestPlatform_AccessPolicy(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformModern)
+	DefaultAccessPolicy(cpu.Platform).Attach(cpu.Memory)
+
+	if err := cpu.Bus.Set8(0x050, 0x00); err == nil {
+		t.Errorf("Bus.Set8() error = nil writing font at 0050")
+	}
+	if err := cpu.Bus.Set8(0x000, 0x00); err != nil {
+		t.Errorf("Bus.Set8() error = %v writing interpreter area below font", err)
+	}
+}
@@ -0,0 +1,141 @@
+package chip8
+
+import "fmt"
+
+type Permission uint8
+
+const (
+	PermRead Permission = 1 << iota
+	PermWrite
+	PermExecute
+)
+
+type AccessKind uint8
+
+const (
+	AccessRead AccessKind = iota
+	AccessWrite
+	AccessExecute
+)
+
+func (kind AccessKind) String() string {
+	switch kind {
+	case AccessRead:
+		return "read"
+	case AccessWrite:
+		return "write"
+	case AccessExecute:
+		return "execute"
+	}
+	return "unknown access"
+}
+
+// Region is an inclusive address range with the accesses allowed to it
+type Region struct {
+	Name  string
+	Start uint16
+	End   uint16
+	Perm  Permission
+}
+
+type AccessViolation struct {
+	Kind   AccessKind
+	Addr   uint16
+	Region string
+}
+
+func (err *AccessViolation) Error() string {
+	return fmt.Sprintf("%v access violation at %04X in %v", err.Kind, err.Addr, err.Region)
+}
+
+type MisalignedPCWarning struct {
+	Addr uint16
+}
+
+func (err *MisalignedPCWarning) Error() string {
+	return fmt.Sprintf("instruction fetch from odd address %04X", err.Addr)
+}
+
+// AccessPolicy restricts memory access by region. Addresses outside every region are unrestricted.
+// Nothing is checked until the policy is attached to a Memory.
+type AccessPolicy struct {
+	Regions          []Region
+	WarnOnly         bool // Report violations through OnWarning and allow the access, rather than failing it
+	WarnMisalignedPC bool
+	OnWarning        func(warning error)
+
+	mem   *Memory
+	hooks []HookID
+}
+
+// Read-only font, interpreter area below the load origin not executable, program space unrestricted
+func DefaultAccessPolicy(platform Platform) *AccessPolicy {
+	return &AccessPolicy{
+		Regions: []Region{
+			{Name: "font", Start: platform.FontAddr, End: platform.FontAddr + uint16(fontSize) - 1, Perm: PermRead},
+			{Name: "interpreter area", Start: 0x000, End: platform.Origin - 1, Perm: PermRead | PermWrite},
+			{Name: "program space", Start: platform.Origin, End: uint16(platform.MemorySize - 1), Perm: PermRead | PermWrite | PermExecute},
+		},
+		WarnMisalignedPC: true,
+	}
+}
+
+func (policy *AccessPolicy) Attach(mem *Memory) {
+	policy.Detach()
+	policy.mem = mem
+
+	policy.hooks = []HookID{
+		mem.AddReadHook(0x0000, 0xFFFF, func(addr uint16, val uint8) (uint8, error) {
+			return val, policy.check(AccessRead, PermRead, addr)
+		}),
+		mem.AddWriteHook(0x0000, 0xFFFF, func(addr uint16, val uint8) (uint8, error) {
+			return val, policy.check(AccessWrite, PermWrite, addr)
+		}),
+		mem.AddExecuteHook(0x0000, 0xFFFF, func(addr uint16) error {
+			if policy.WarnMisalignedPC && addr%2 != 0 {
+				policy.warn(&MisalignedPCWarning{Addr: addr})
+			}
+			return policy.check(AccessExecute, PermExecute, addr)
+		}),
+	}
+}
+
+func (policy *AccessPolicy) Detach() {
+	if policy.mem == nil {
+		return
+	}
+
+	for _, id := range policy.hooks {
+		policy.mem.RemoveHook(id)
+	}
+
+	policy.mem = nil
+	policy.hooks = nil
+}
+
+func (policy *AccessPolicy) check(kind AccessKind, perm Permission, addr uint16) error {
+	for _, region := range policy.Regions {
+		if addr < region.Start || addr > region.End {
+			continue
+		}
+
+		if region.Perm&perm != 0 {
+			return nil
+		}
+
+		violation := &AccessViolation{Kind: kind, Addr: addr, Region: region.Name}
+		if policy.WarnOnly {
+			policy.warn(violation)
+			return nil
+		}
+		return violation
+	}
+
+	return nil
+}
+
+func (policy *AccessPolicy) warn(warning error) {
+	if policy.OnWarning != nil {
+		policy.OnWarning(warning)
+	}
+}
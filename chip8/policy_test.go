@@ -0,0 +1,159 @@
+package chip8
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAccessPolicy_Violations(t *testing.T) {
+	tests := map[string]struct {
+		access   func(cpu *Cpu) error
+		wantKind AccessKind
+		wantAddr uint16
+		wantErr  bool
+	}{
+		"write font": {
+			access:   func(cpu *Cpu) error { return cpu.Bus.Set8(0x010, 0xFF) },
+			wantKind: AccessWrite,
+			wantAddr: 0x010,
+			wantErr:  true,
+		},
+		"write font last byte with Set16": {
+			access:   func(cpu *Cpu) error { return cpu.Bus.Set16(0x04F, 0xFFFF) },
+			wantKind: AccessWrite,
+			wantAddr: 0x04F,
+			wantErr:  true,
+		},
+		"read font": {
+			access: func(cpu *Cpu) error {
+				_, err := cpu.Bus.Get16(0x000)
+				return err
+			},
+		},
+		"write interpreter area": {
+			access: func(cpu *Cpu) error { return cpu.Bus.Set8(0x100, 0xFF) },
+		},
+		"write program space": {
+			access: func(cpu *Cpu) error { return cpu.Bus.Set8(0x300, 0xFF) },
+		},
+		"execute interpreter area": {
+			access: func(cpu *Cpu) error {
+				cpu.PC = 0x100
+				return cpu.Tick()
+			},
+			wantKind: AccessExecute,
+			wantAddr: 0x100,
+			wantErr:  true,
+		},
+		"execute program space": {
+			access: func(cpu *Cpu) error {
+				cpu.Memory.Memory[0x200] = 0x60
+				return cpu.Tick()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			DefaultAccessPolicy(cpu.Platform).Attach(cpu.Memory)
+
+			err := tt.access(cpu)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("access error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			var violation *AccessViolation
+			if !errors.As(err, &violation) {
+				t.Fatalf("access error = %v, want *AccessViolation", err)
+			}
+			if violation.Kind != tt.wantKind || violation.Addr != tt.wantAddr {
+				t.Errorf("AccessViolation = %v at %04X, want %v at %04X", violation.Kind, violation.Addr, tt.wantKind, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestAccessPolicy_FontUnchangedAfterDeniedWrite(t *testing.T) {
+	cpu := NewCpu()
+	DefaultAccessPolicy(cpu.Platform).Attach(cpu.Memory)
+
+	before := cpu.Memory.Memory[0x000]
+	cpu.Bus.Set8(0x000, ^before)
+
+	if cpu.Memory.Memory[0x000] != before {
+		t.Errorf("font byte = %02X after denied write, want %02X", cpu.Memory.Memory[0x000], before)
+	}
+}
+
+func TestAccessPolicy_WarnOnly(t *testing.T) {
+	cpu := NewCpu()
+
+	var warnings []error
+	policy := DefaultAccessPolicy(cpu.Platform)
+	policy.WarnOnly = true
+	policy.OnWarning = func(warning error) { warnings = append(warnings, warning) }
+	policy.Attach(cpu.Memory)
+
+	if err := cpu.Bus.Set8(0x000, 0xAA); err != nil {
+		t.Fatalf("Bus.Set8() error = %v, want nil in warn-only mode", err)
+	}
+	if cpu.Memory.Memory[0x000] != 0xAA {
+		t.Errorf("font byte = %02X, want AA written through in warn-only mode", cpu.Memory.Memory[0x000])
+	}
+
+	var violation *AccessViolation
+	if len(warnings) != 1 || !errors.As(warnings[0], &violation) || violation.Kind != AccessWrite {
+		t.Errorf("warnings = %v, want one write violation", warnings)
+	}
+}
+
+func TestAccessPolicy_MisalignedPC(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x12, 0x03, 0x00, 0x60, 0x01}) // JP 0x203; 0x203: LD V0, 0x01
+
+	var warnings []error
+	policy := DefaultAccessPolicy(cpu.Platform)
+	policy.OnWarning = func(warning error) { warnings = append(warnings, warning) }
+	policy.Attach(cpu.Memory)
+
+	for range 2 {
+		if err := cpu.Tick(); err != nil {
+			t.Fatalf("Cpu.Tick() error = %v, want misaligned PC to only warn", err)
+		}
+	}
+
+	var misaligned *MisalignedPCWarning
+	if len(warnings) != 1 || !errors.As(warnings[0], &misaligned) || misaligned.Addr != 0x203 {
+		t.Errorf("warnings = %v, want one misaligned fetch at 0203", warnings)
+	}
+	if cpu.V[0] != 0x01 {
+		t.Errorf("V0 = %02X, want 01 from the misaligned instruction", cpu.V[0])
+	}
+}
+
+func TestAccessPolicy_Detach(t *testing.T) {
+	cpu := NewCpu()
+
+	policy := DefaultAccessPolicy(cpu.Platform)
+	policy.Attach(cpu.Memory)
+	policy.Detach()
+
+	if err := cpu.Bus.Set8(0x000, 0xAA); err != nil {
+		t.Errorf("Bus.Set8() error = %v after Detach, want nil", err)
+	}
+	if cpu.Memory.hooks != nil {
+		t.Errorf("Memory still has hooks after Detach")
+	}
+}
+
+func TestAccessPolicy_OffByDefault(t *testing.T) {
+	cpu := NewCpu()
+
+	if err := cpu.Bus.Set8(0x000, 0xAA); err != nil {
+		t.Errorf("Bus.Set8() error = %v on font without a policy, want nil", err)
+	}
+}
@@ -0,0 +1,209 @@
+package recomp
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/frasmataz/go-chip8/chip8"
+)
+
+const origin = 0x200
+
+type block struct {
+	start uint16
+	end   uint16 // Address after the last instruction
+	insts []chip8.Instruction
+}
+
+// Terminators end a block. The ones with statically known successors get native control flow;
+// the rest leave their successor to Step's interpreter fallback.
+func endsBlock(op chip8.Op) bool {
+	switch op {
+	case chip8.OpJP, chip8.OpCALL, chip8.OpRET, chip8.OpJP_v0_addr,
+		chip8.OpSE_v_byte, chip8.OpSNE_v_byte, chip8.OpSE_v1_v2, chip8.OpSNE_v1_v2, chip8.OpSKP, chip8.OpSKNP,
+		chip8.OpCLS, chip8.OpDRW, chip8.OpLD_v_k,
+		chip8.OpSYS, chip8.OpEXIT, chip8.OpLD_i_long, chip8.OpInvalid:
+		return true
+	}
+	return false
+}
+
+func successors(inst chip8.Instruction, addr uint16) []uint16 {
+	next := addr + 2
+
+	switch inst.Op {
+	case chip8.OpJP:
+		return []uint16{inst.NNN}
+	case chip8.OpCALL:
+		return []uint16{inst.NNN, next}
+	case chip8.OpRET, chip8.OpJP_v0_addr, chip8.OpEXIT, chip8.OpLD_i_long:
+		return nil
+	case chip8.OpSE_v_byte, chip8.OpSNE_v_byte, chip8.OpSE_v1_v2, chip8.OpSNE_v1_v2, chip8.OpSKP, chip8.OpSKNP:
+		return []uint16{next, next + 2}
+	}
+	return []uint16{next}
+}
+
+// Control-flow discovery from the load address. Blocks may overlap where code jumps into the middle of another block.
+func discover(rom []byte) []block {
+	romEnd := origin + len(rom)
+
+	blocks := make(map[uint16]block)
+	work := []uint16{origin}
+
+	for len(work) > 0 {
+		start := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		if _, ok := blocks[start]; ok || int(start) < origin || int(start)+2 > romEnd || start%2 != 0 {
+			continue
+		}
+
+		b := block{start: start}
+		addr := start
+		for int(addr)+2 <= romEnd {
+			inst := chip8.Decode(uint16(rom[addr-origin])<<8 | uint16(rom[addr-origin+1]))
+			b.insts = append(b.insts, inst)
+
+			if endsBlock(inst.Op) {
+				work = append(work, successors(inst, addr)...)
+				addr += 2
+				break
+			}
+			addr += 2
+		}
+		b.end = addr
+
+		blocks[start] = b
+	}
+
+	sorted := make([]block, 0, len(blocks))
+	for _, b := range blocks {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+
+	return sorted
+}
+
+// Generates a Go package that runs rom natively against a chip8.Cpu
+func Generate(rom []byte, pkg string) ([]byte, error) {
+	if len(rom) > chip8.MemorySize-origin {
+		return nil, fmt.Errorf("ROM too large: %v bytes, max %v", len(rom), chip8.MemorySize-origin)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("// Code generated by chip8-recomp. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "package %s\n\n", pkg)
+	sb.WriteString("import (\n\t\"bytes\"\n\n\t\"github.com/frasmataz/go-chip8/chip8\"\n)\n\n")
+
+	sb.WriteString("var ROM = []byte{")
+	for i, b := range rom {
+		if i%16 == 0 {
+			sb.WriteString("\n\t")
+		}
+		fmt.Fprintf(&sb, "0x%02X, ", b)
+	}
+	sb.WriteString("\n}\n\n")
+
+	sb.WriteString("// Returns a Cpu with the ROM loaded\n")
+	sb.WriteString("func New() *chip8.Cpu {\n\tcpu := chip8.NewCpu()\n\tcpu.LoadROM(ROM)\n\treturn cpu\n}\n\n")
+
+	sb.WriteString("// Runs the block at cpu.PC and returns the number of instructions executed. Addresses that are not\n")
+	sb.WriteString("// a known block start, blocks whose code has been modified, and Cpus that need every instruction to go\n")
+	sb.WriteString("// through Tick run a single interpreted instruction.\n")
+	sb.WriteString("func Step(cpu *chip8.Cpu) (int, error) {\n\tif cpu.NeedsTick() || cpu.Extensions() != nil {\n\t\treturn 1, cpu.Tick()\n\t}\n\n\tswitch cpu.PC {\n")
+
+	blocks := discover(rom)
+	for _, b := range blocks {
+		fmt.Fprintf(&sb, "\tcase 0x%03X:\n\t\treturn block%03X(cpu)\n", b.start, b.start)
+	}
+	sb.WriteString("\t}\n\treturn 1, cpu.Tick()\n}\n\n")
+
+	sb.WriteString("// Runs blocks until at least n instructions have executed\n")
+	sb.WriteString("func Run(cpu *chip8.Cpu, n int) (int, error) {\n\texecuted := 0\n\tfor executed < n {\n")
+	sb.WriteString("\t\tcount, err := Step(cpu)\n\t\texecuted += count\n\t\tif err != nil {\n\t\t\treturn executed, err\n\t\t}\n")
+	sb.WriteString("\t}\n\treturn executed, nil\n}\n")
+
+	for _, b := range blocks {
+		writeBlock(&sb, b)
+	}
+
+	return format.Source([]byte(sb.String()))
+}
+
+func writeBlock(sb *strings.Builder, b block) {
+	fmt.Fprintf(sb, "\nfunc block%03X(cpu *chip8.Cpu) (int, error) {\n", b.start)
+	fmt.Fprintf(sb, "\tif !bytes.Equal(cpu.Memory.Memory[0x%03X:0x%03X], ROM[0x%03X:0x%03X]) {\n\t\treturn 1, cpu.Tick()\n\t}\n\n",
+		b.start, b.end, b.start-origin, b.end-origin)
+
+	for i, inst := range b.insts {
+		addr := b.start + uint16(i)*2
+		count := i + 1
+
+		fmt.Fprintf(sb, "\t// %03X: %v\n", addr, inst)
+		writeInstruction(sb, inst, addr, count, i == len(b.insts)-1)
+	}
+
+	last := b.insts[len(b.insts)-1]
+	if !endsBlock(last.Op) {
+		fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, nil\n", b.end, len(b.insts))
+	}
+
+	sb.WriteString("}\n")
+}
+
+func writeInstruction(sb *strings.Builder, inst chip8.Instruction, addr uint16, count int, last bool) {
+	next := addr + 2
+
+	switch inst.Op {
+	case chip8.OpLD_v_byte:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] = 0x%02X\n", inst.X, inst.NN)
+	case chip8.OpADD_v_byte:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] += 0x%02X\n", inst.X, inst.NN)
+	case chip8.OpLD_v1_v2:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] = cpu.V[0x%X]\n", inst.X, inst.Y)
+	case chip8.OpOR_v1_v2:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] |= cpu.V[0x%X]\n", inst.X, inst.Y)
+	case chip8.OpAND_v1_v2:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] &= cpu.V[0x%X]\n", inst.X, inst.Y)
+	case chip8.OpXOR_v1_v2:
+		fmt.Fprintf(sb, "\tcpu.V[0x%X] ^= cpu.V[0x%X]\n", inst.X, inst.Y)
+	case chip8.OpADD_v1_v2:
+		fmt.Fprintf(sb, "\t{\n\t\tsum := uint16(cpu.V[0x%X]) + uint16(cpu.V[0x%X])\n", inst.X, inst.Y)
+		fmt.Fprintf(sb, "\t\tcpu.V[0x%X] = uint8(sum)\n\t\tcpu.V[0xF] = uint8(sum >> 8)\n\t}\n", inst.X)
+	case chip8.OpJP:
+		if inst.NNN <= 0xFFE {
+			fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, nil\n", inst.NNN, count)
+		} else {
+			fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, cpu.JP(chip8.Decode(0x%04X))\n", next, count, inst.Opcode)
+		}
+	case chip8.OpCALL:
+		fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, cpu.CALL(chip8.Decode(0x%04X))\n", next, count, inst.Opcode)
+	case chip8.OpRET:
+		fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, cpu.RET()\n", next, count)
+	case chip8.OpCLS:
+		fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\treturn %d, cpu.CLS()\n", next, count)
+	case chip8.OpSE_v_byte:
+		writeSkip(sb, fmt.Sprintf("cpu.V[0x%X] == 0x%02X", inst.X, inst.NN), next, count)
+	case chip8.OpSNE_v_byte:
+		writeSkip(sb, fmt.Sprintf("cpu.V[0x%X] != 0x%02X", inst.X, inst.NN), next, count)
+	case chip8.OpSE_v1_v2:
+		writeSkip(sb, fmt.Sprintf("cpu.V[0x%X] == cpu.V[0x%X]", inst.X, inst.Y), next, count)
+	default:
+		// Anything without a native translation goes through the interpreter
+		fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n", addr)
+		if last && endsBlock(inst.Op) {
+			fmt.Fprintf(sb, "\treturn %d, cpu.Tick()\n", count)
+		} else {
+			fmt.Fprintf(sb, "\tif err := cpu.Tick(); err != nil {\n\t\treturn %d, err\n\t}\n", count)
+		}
+	}
+}
+
+func writeSkip(sb *strings.Builder, condition string, next uint16, count int) {
+	fmt.Fprintf(sb, "\tcpu.PC = 0x%03X\n\tif %s {\n\t\tcpu.PC += 2\n\t}\n\treturn %d, nil\n", next, condition, count)
+}
@@ -0,0 +1,229 @@
+package recomp
+
+import (
+	"go/parser"
+	"go/token"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	rom := []byte{
+		0x60, 0x05, // 200: LD V0, 0x05
+		0x22, 0x0A, // 202: CALL 20A
+		0x12, 0x12, // 204: JP 212
+		0xFF, 0xFF, // 206: unreachable
+		0xFF, 0xFF, // 208: unreachable
+		0x71, 0x01, // 20A: ADD V1, 0x01
+		0x31, 0x10, // 20C: SE V1, 0x10
+		0x00, 0xEE, // 20E: RET
+		0x00, 0xEE, // 210: RET
+		0x12, 0x14, // 212: JP 214
+		0x01, 0x23, // 214: SYS 123
+		0x60, 0x01, // 216: LD V0, 0x01
+		0xFF, 0xFF, // 218: invalid
+		0x00, 0xEE, // 21A: RET
+	}
+
+	want := []struct {
+		start uint16
+		end   uint16
+	}{
+		{0x200, 0x204},
+		{0x204, 0x206},
+		{0x20A, 0x20E},
+		{0x20E, 0x210},
+		{0x210, 0x212},
+		{0x212, 0x214},
+		{0x214, 0x216},
+		{0x216, 0x21A},
+		{0x21A, 0x21C},
+	}
+
+	got := discover(rom)
+
+	if len(got) != len(want) {
+		t.Fatalf("discover() found %v blocks, want %v: %+v", len(got), len(want), got)
+	}
+
+	for i, b := range got {
+		if b.start != want[i].start || b.end != want[i].end {
+			t.Errorf("discover() block %v = %03X-%03X, want %03X-%03X", i, b.start, b.end, want[i].start, want[i].end)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rom := randomROM(0x100)
+
+	source, err := Generate(rom, "game")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "game.go", source, 0)
+	if err != nil {
+		t.Fatalf("Generate() produced invalid Go: %v\n%s", err, source)
+	}
+
+	if file.Name.Name != "game" {
+		t.Errorf("Generate() package = %v, want game", file.Name.Name)
+	}
+	if !strings.Contains(string(source), "if cpu.NeedsTick() || cpu.Extensions() != nil {") {
+		t.Errorf("Generate() Step does not fall back to Tick when the Cpu needs it")
+	}
+}
+
+func TestGenerate_TooLarge(t *testing.T) {
+	_, err := Generate(make([]byte, 0xE01), "game")
+	if err == nil {
+		t.Errorf("Generate() did not throw error as wanted")
+	}
+}
+
+// Random program of every implemented op plus a few the interpreter ignores, jumping within the program
+func randomROM(size int) []byte {
+	rom := make([]byte, 0, size+2)
+
+	for len(rom) < size {
+		x, y, nn := uint16(rand.Intn(0x10)), uint16(rand.Intn(0x10)), uint16(rand.Intn(0x100))
+		target := 0x200 + uint16(rand.Intn(size/2))*2
+
+		var opcode uint16
+		switch rand.Intn(14) {
+		case 0:
+			opcode = 0x6000 | x<<8 | nn
+		case 1:
+			opcode = 0x7000 | x<<8 | nn
+		case 2, 3:
+			opcode = 0x8000 | x<<8 | y<<4 | uint16(rand.Intn(5))
+		case 4:
+			opcode = 0x3000 | x<<8 | nn
+		case 5:
+			opcode = 0x4000 | x<<8 | nn
+		case 6:
+			opcode = 0x5000 | x<<8 | y<<4
+		case 7:
+			opcode = 0x1000 | target
+		case 8:
+			opcode = 0x2000 | target
+		case 9:
+			opcode = 0x00EE
+		case 10:
+			opcode = 0x00E0
+		case 11:
+			opcode = 0xD000 | x<<8 | y<<4 | uint16(rand.Intn(0x10))
+		case 12:
+			opcode = 0xB000 | target
+		default:
+			opcode = 0x0123
+		}
+
+		rom = append(rom, uint8(opcode>>8), uint8(opcode))
+	}
+
+	return append(rom, 0x12, 0x00)
+}
+
+const differentialHarness = `package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/frasmataz/go-chip8/chip8"
+	"github.com/frasmataz/go-chip8/chip8/recomp/DIR/rom"
+)
+
+// SYS calls jump back to the start, so blocks must not run on past them
+func sys(cpu *chip8.Cpu, addr uint16) error {
+	cpu.PC = 0x200
+	return nil
+}
+
+func main() {
+	native := rom.New()
+	interpreted := rom.New()
+	native.Sys, interpreted.Sys = sys, sys
+
+	for step := 0; step < 5000; step++ {
+		count, nativeErr := rom.Step(native)
+
+		var interpretedErr error
+		for range count {
+			interpretedErr = interpreted.Tick()
+			if interpretedErr != nil {
+				break
+			}
+		}
+
+		if (nativeErr != nil) != (interpretedErr != nil) {
+			fmt.Printf("step %v: interpreter error = %v, native error = %v\n", step, interpretedErr, nativeErr)
+			os.Exit(1)
+		}
+
+		if !bytes.Equal(native.SaveState(), interpreted.SaveState()) {
+			fmt.Printf("step %v: native state diverged:\n%v\nwant:\n%v\n", step, native.GetPrettyCpuState(), interpreted.GetPrettyCpuState())
+			os.Exit(1)
+		}
+
+		if nativeErr != nil {
+			break
+		}
+
+		// Self-modification, applied to both
+		if step%97 == 0 {
+			native.Memory.Set16(0x200, 0x7F01)
+			interpreted.Memory.Set16(0x200, 0x7F01)
+		}
+	}
+
+	fmt.Println("ok")
+}
+`
+
+// Builds generated code for random ROMs and runs it in lockstep with the interpreter
+func TestGenerate_MatchesInterpreter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated code")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	const n_tests = 5
+
+	os.MkdirAll("testdata", 0755)
+	t.Cleanup(func() { os.Remove("testdata") })
+
+	for i := 0; i < n_tests; i++ {
+		dir, err := os.MkdirTemp("testdata", "gen")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		source, err := Generate(randomROM(0x100), "rom")
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		os.Mkdir(filepath.Join(dir, "rom"), 0755)
+		os.WriteFile(filepath.Join(dir, "rom", "rom.go"), source, 0644)
+
+		harness := strings.ReplaceAll(differentialHarness, "DIR", filepath.ToSlash(dir))
+		os.WriteFile(filepath.Join(dir, "main.go"), []byte(harness), 0644)
+
+		out, err := exec.Command(goTool, "run", "./"+filepath.ToSlash(dir)).CombinedOutput()
+		if err != nil || strings.TrimSpace(string(out)) != "ok" {
+			t.Fatalf("generated code diverged from interpreter: %v\n%s", err, out)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package chip8
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const DefaultKeyframeInterval = 60
+
+// RewindBuffer holds one snapshot per frame. Every KeyframeInterval frames a full state is stored;
+// the frames in between are stored as run-length encoded XOR deltas against that keyframe.
+// The oldest frames are dropped once the stored data exceeds the byte budget.
+type RewindBuffer struct {
+	KeyframeInterval int
+
+	budget  int
+	size    int
+	entries []rewindEntry
+}
+
+type rewindEntry struct {
+	keyframe bool
+	data     []byte
+}
+
+func NewRewindBuffer(budget int) *RewindBuffer {
+	return &RewindBuffer{
+		KeyframeInterval: DefaultKeyframeInterval,
+		budget:           budget,
+	}
+}
+
+// Number of frames currently held
+func (rb *RewindBuffer) Len() int {
+	return len(rb.entries)
+}
+
+// Number of bytes of snapshot data currently held
+func (rb *RewindBuffer) Size() int {
+	return rb.size
+}
+
+func (rb *RewindBuffer) Push(cpu *Cpu) {
+	state := cpu.SaveState()
+
+	entry := rewindEntry{keyframe: true, data: state}
+
+	if key := rb.lastKeyframe(); key >= 0 && len(rb.entries)-key < rb.KeyframeInterval {
+		entry = rewindEntry{keyframe: false, data: encodeDelta(rb.entries[key].data, state)}
+	}
+
+	rb.entries = append(rb.entries, entry)
+	rb.size += len(entry.data)
+
+	rb.evict()
+}
+
+// A RewindBuffer subscribed to a Scheduler pushes a snapshot at the end of every frame
+func (rb *RewindBuffer) BeginFrame(cpu *Cpu) error {
+	return nil
+}
+
+func (rb *RewindBuffer) EndFrame(cpu *Cpu) error {
+	rb.Push(cpu)
+	return nil
+}
+
+// Restores the state from n frames before the most recent Push, and discards every newer frame
+// so that execution can resume from there
+func (rb *RewindBuffer) Rewind(cpu *Cpu, n int) error {
+	if n < 0 || n >= len(rb.entries) {
+		return fmt.Errorf("cannot rewind %v frames, buffer holds %v", n, len(rb.entries))
+	}
+
+	target := len(rb.entries) - 1 - n
+
+	state, err := rb.stateAt(target)
+	if err != nil {
+		return err
+	}
+
+	err = cpu.LoadState(state)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range rb.entries[target+1:] {
+		rb.size -= len(entry.data)
+	}
+	rb.entries = rb.entries[:target+1]
+
+	return nil
+}
+
+func (rb *RewindBuffer) stateAt(index int) ([]byte, error) {
+	entry := rb.entries[index]
+	if entry.keyframe {
+		return entry.data, nil
+	}
+
+	key := index
+	for !rb.entries[key].keyframe {
+		key--
+	}
+
+	return decodeDelta(rb.entries[key].data, entry.data)
+}
+
+func (rb *RewindBuffer) lastKeyframe() int {
+	for i := len(rb.entries) - 1; i >= 0; i-- {
+		if rb.entries[i].keyframe {
+			return i
+		}
+	}
+	return -1
+}
+
+// Drops the oldest keyframe and its deltas until the budget is met, always keeping the newest group
+func (rb *RewindBuffer) evict() {
+	for rb.size > rb.budget {
+		end := 1
+		for end < len(rb.entries) && !rb.entries[end].keyframe {
+			end++
+		}
+		if end == len(rb.entries) {
+			return
+		}
+
+		for _, entry := range rb.entries[:end] {
+			rb.size -= len(entry.data)
+		}
+		rb.entries = append(rb.entries[:0], rb.entries[end:]...)
+	}
+}
+
+// Delta encoding is a sequence of (zero run length, literal length, literal bytes) over base XOR state,
+// with lengths as uvarints
+func encodeDelta(base []byte, state []byte) []byte {
+	var delta []byte
+
+	for i := 0; i < len(state); {
+		zeros := i
+		for i < len(state) && base[i] == state[i] {
+			i++
+		}
+		zeros = i - zeros
+
+		literal := i
+		for i < len(state) && base[i] != state[i] {
+			i++
+		}
+
+		delta = binary.AppendUvarint(delta, uint64(zeros))
+		delta = binary.AppendUvarint(delta, uint64(i-literal))
+		for j := literal; j < i; j++ {
+			delta = append(delta, base[j]^state[j])
+		}
+	}
+
+	return delta
+}
+
+func decodeDelta(base []byte, delta []byte) ([]byte, error) {
+	state := make([]byte, len(base))
+	copy(state, base)
+
+	pos := 0
+	for len(delta) > 0 {
+		zeros, n := binary.Uvarint(delta)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt rewind delta at state offset %v", pos)
+		}
+		delta = delta[n:]
+
+		literal, n := binary.Uvarint(delta)
+		if n <= 0 || uint64(len(delta)-n) < literal {
+			return nil, fmt.Errorf("corrupt rewind delta at state offset %v", pos)
+		}
+		delta = delta[n:]
+
+		pos += int(zeros)
+		if pos+int(literal) > len(state) {
+			return nil, fmt.Errorf("rewind delta overruns state: offset %v, size %v", pos+int(literal), len(state))
+		}
+
+		for i := range int(literal) {
+			state[pos+i] ^= delta[i]
+		}
+		pos += int(literal)
+		delta = delta[literal:]
+	}
+
+	return state, nil
+}
@@ -0,0 +1,139 @@
+package chip8
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Runs a few register and memory changes per frame, keeping every pushed state for comparison
+func runRewindFrames(cpu *Cpu, rb *RewindBuffer, frames int) [][]byte {
+	var states [][]byte
+
+	for range frames {
+		cpu.V[rand.Intn(0x10)] = uint8(rand.Intn(0x100))
+		cpu.Memory.Set8(uint16(0x200+rand.Intn(0x100)), uint8(rand.Intn(0x100)))
+		cpu.Display.Set(uint(rand.Intn(width)), uint(rand.Intn(height)), true)
+
+		rb.Push(cpu)
+		states = append(states, cpu.SaveState())
+	}
+
+	return states
+}
+
+func TestRewindBuffer_Rewind(t *testing.T) {
+	tests := map[string]struct {
+		frames  int
+		rewind  int
+		wantErr bool
+	}{
+		"latest frame": {
+			frames: 10,
+			rewind: 0,
+		},
+		"keyframe": {
+			frames: 100,
+			rewind: 99,
+		},
+		"delta frame": {
+			frames: 100,
+			rewind: 17,
+		},
+		"too far": {
+			frames:  10,
+			rewind:  10,
+			wantErr: true,
+		},
+		"negative": {
+			frames:  10,
+			rewind:  -1,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := getRandomCpuState()
+			rb := NewRewindBuffer(1 << 20)
+
+			states := runRewindFrames(cpu, rb, test.frames)
+
+			err := rb.Rewind(cpu, test.rewind)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RewindBuffer.Rewind() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !bytes.Equal(cpu.SaveState(), states[len(states)-1-test.rewind]) {
+				t.Errorf("RewindBuffer.Rewind() restored wrong state: %v", cpu.GetPrettyCpuState())
+			}
+
+			if rb.Len() != test.frames-test.rewind {
+				t.Errorf("RewindBuffer.Len() = %v after rewind, want %v", rb.Len(), test.frames-test.rewind)
+			}
+		})
+	}
+}
+
+func TestRewindBuffer_Resume(t *testing.T) {
+	cpu := getRandomCpuState()
+	rb := NewRewindBuffer(1 << 20)
+
+	runRewindFrames(cpu, rb, 50)
+
+	err := rb.Rewind(cpu, 20)
+	if err != nil {
+		t.Fatalf("RewindBuffer.Rewind() error = %v", err)
+	}
+
+	states := runRewindFrames(cpu, rb, 30)
+
+	err = rb.Rewind(cpu, 5)
+	if err != nil {
+		t.Fatalf("RewindBuffer.Rewind() error = %v", err)
+	}
+
+	if !bytes.Equal(cpu.SaveState(), states[len(states)-6]) {
+		t.Errorf("RewindBuffer.Rewind() after resume restored wrong state")
+	}
+}
+
+func TestRewindBuffer_Budget(t *testing.T) {
+	cpu := getRandomCpuState()
+	budget := 4 * cpu.stateSize()
+
+	rb := NewRewindBuffer(budget)
+	rb.KeyframeInterval = 10
+
+	runRewindFrames(cpu, rb, 500)
+
+	if rb.Size() > budget {
+		t.Errorf("RewindBuffer.Size() = %v, over budget %v", rb.Size(), budget)
+	}
+
+	if rb.Len() < rb.KeyframeInterval {
+		t.Errorf("RewindBuffer.Len() = %v, want at least one keyframe group", rb.Len())
+	}
+
+	if !rb.entries[0].keyframe {
+		t.Errorf("RewindBuffer oldest frame is not a keyframe after eviction")
+	}
+
+	err := rb.Rewind(cpu, rb.Len()-1)
+	if err != nil {
+		t.Errorf("RewindBuffer.Rewind() to oldest frame error = %v", err)
+	}
+}
+
+func TestRewindBuffer_DeltaCompression(t *testing.T) {
+	cpu := getRandomCpuState()
+	rb := NewRewindBuffer(1 << 20)
+
+	runRewindFrames(cpu, rb, DefaultKeyframeInterval)
+
+	if rb.Size() > DefaultKeyframeInterval*cpu.stateSize()/10 {
+		t.Errorf("RewindBuffer.Size() = %v for %v frames, deltas not compressed", rb.Size(), rb.Len())
+	}
+}
@@ -0,0 +1,193 @@
+package chip8
+
+import (
+	"context"
+	"fmt"
+)
+
+const DefaultInstructionsPerFrame = 11 // About 660 instructions per second
+
+// FrameSubscriber is told about every frame a Scheduler runs. BeginFrame is called before the frame's
+// first instruction, EndFrame after the timers have ticked at the end of it. An error from either stops the run.
+type FrameSubscriber interface {
+	BeginFrame(cpu *Cpu) error
+	EndFrame(cpu *Cpu) error
+}
+
+// FrameFunc adapts a function to a FrameSubscriber that is called at the end of every frame
+type FrameFunc func(cpu *Cpu) error
+
+func (fn FrameFunc) BeginFrame(cpu *Cpu) error {
+	return nil
+}
+
+func (fn FrameFunc) EndFrame(cpu *Cpu) error {
+	return fn(cpu)
+}
+
+type SubscriptionID int
+
+type subscription struct {
+	id         SubscriptionID
+	subscriber FrameSubscriber
+}
+
+// Scheduler runs a Cpu in 60 Hz frames. It is the one place that decides what a frame is: how many
+// instructions run in it, when the timers tick, and when recorders, rewind and renderers see the result.
+type Scheduler struct {
+	InstructionsPerFrame int
+	CyclesPerFrame       uint64 // When non-zero and the Cpu has a timing model, frames are measured in Cpu.Cycles instead
+	DisplayWait          bool   // DRW waits for the next frame unless it starts this one, as the VIP waits for the display interrupt before drawing
+
+	cpu         *Cpu
+	frame       uint64
+	subscribers []subscription
+	nextID      SubscriptionID
+}
+
+// Frames follow the Cpu's VIP timing model if it has one, and are DefaultInstructionsPerFrame long otherwise
+func NewScheduler(cpu *Cpu) *Scheduler {
+	scheduler := &Scheduler{
+		InstructionsPerFrame: DefaultInstructionsPerFrame,
+		cpu:                  cpu,
+	}
+
+	switch timing := cpu.Timing.(type) {
+	case VIPTiming:
+		scheduler.CyclesPerFrame = timing.CyclesPerFrame
+	case *VIPTiming:
+		if timing != nil {
+			scheduler.CyclesPerFrame = timing.CyclesPerFrame
+		}
+	}
+
+	return scheduler
+}
+
+func (scheduler *Scheduler) Cpu() *Cpu {
+	return scheduler.cpu
+}
+
+// Number of frames run so far
+func (scheduler *Scheduler) Frame() uint64 {
+	return scheduler.frame
+}
+
+func (scheduler *Scheduler) Subscribe(subscriber FrameSubscriber) SubscriptionID {
+	scheduler.nextID++
+	scheduler.subscribers = append(scheduler.subscribers, subscription{id: scheduler.nextID, subscriber: subscriber})
+	return scheduler.nextID
+}
+
+func (scheduler *Scheduler) Unsubscribe(id SubscriptionID) {
+	for i, sub := range scheduler.subscribers {
+		if sub.id == id {
+			scheduler.subscribers = append(scheduler.subscribers[:i], scheduler.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (scheduler *Scheduler) RunFrame() error {
+	cpu := scheduler.cpu
+
+	for _, sub := range scheduler.subscribers {
+		err := sub.subscriber.BeginFrame(cpu)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := scheduler.runInstructions()
+	if err != nil {
+		return err
+	}
+
+	if cpu.DT > 0 {
+		cpu.setTimer(TimerDelay, cpu.DT-1)
+	}
+	if cpu.ST > 0 {
+		cpu.setTimer(TimerSound, cpu.ST-1)
+	}
+	scheduler.frame++
+
+	for _, sub := range scheduler.subscribers {
+		err := sub.subscriber.EndFrame(cpu)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (scheduler *Scheduler) runInstructions() error {
+	cpu := scheduler.cpu
+
+	// Without a timing model Cycles never advances, so frames fall back to counting instructions
+	timed := scheduler.CyclesPerFrame > 0 && cpu.Timing != nil
+
+	var frameEnd uint64
+	if timed {
+		frameEnd = (cpu.Cycles/scheduler.CyclesPerFrame + 1) * scheduler.CyclesPerFrame
+	}
+
+	for executed := 0; ; executed++ {
+		if timed {
+			if cpu.Cycles >= frameEnd {
+				return nil
+			}
+		} else if executed >= scheduler.InstructionsPerFrame {
+			return nil
+		}
+
+		inst, err := cpu.fetch()
+		if err != nil {
+			return err
+		}
+
+		if scheduler.DisplayWait && inst.Op == OpDRW && executed > 0 {
+			// The rest of the frame is spent waiting for the display interrupt. DRW is fetched again to start the
+			// next frame, so execute hooks see it twice.
+			if frameEnd > cpu.Cycles {
+				cpu.Cycles = frameEnd
+			}
+			return nil
+		}
+
+		pc, cycles := cpu.PC, cpu.Cycles
+		err = cpu.run(inst)
+		if err != nil {
+			return err
+		}
+		if timed && cpu.Cycles == cycles {
+			return fmt.Errorf("instruction %04X at %04X took no cycles, so the frame cannot end", inst.Opcode, pc)
+		}
+	}
+}
+
+func (scheduler *Scheduler) RunFrames(n int) error {
+	for range n {
+		err := scheduler.RunFrame()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs frames back to back, as fast as possible, until ctx is done or a frame fails
+func (scheduler *Scheduler) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		err := scheduler.RunFrame()
+		if err != nil {
+			return err
+		}
+	}
+}
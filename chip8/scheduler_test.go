@@ -0,0 +1,274 @@
+package chip8
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ADD V0, 0x01 / DRW V0, V0, 0 / JP 0x200
+var schedulerTestROM = []byte{0x70, 0x01, 0xD0, 0x00, 0x12, 0x00}
+
+func newSchedulerTestCpu() *Cpu {
+	cpu := NewCpu()
+	cpu.LoadROM(schedulerTestROM)
+	return cpu
+}
+
+func TestScheduler_RunFrames(t *testing.T) {
+	tests := map[string]struct {
+		instructionsPerFrame int
+		displayWait          bool
+		frames               int
+		wantV0               uint8
+	}{
+		"one frame":           {instructionsPerFrame: 10, frames: 1, wantV0: 4},
+		"three frames":        {instructionsPerFrame: 10, frames: 3, wantV0: 10},
+		"display wait":        {instructionsPerFrame: 10, displayWait: true, frames: 1, wantV0: 1},
+		"display wait frames": {instructionsPerFrame: 10, displayWait: true, frames: 5, wantV0: 5},
+		"short frames":        {instructionsPerFrame: 1, displayWait: true, frames: 3, wantV0: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newSchedulerTestCpu()
+			scheduler := NewScheduler(cpu)
+			scheduler.InstructionsPerFrame = test.instructionsPerFrame
+			scheduler.DisplayWait = test.displayWait
+
+			err := scheduler.RunFrames(test.frames)
+			if err != nil {
+				t.Fatalf("Scheduler.RunFrames() error = %v", err)
+			}
+
+			if cpu.V[0] != test.wantV0 || scheduler.Frame() != uint64(test.frames) {
+				t.Errorf("after %v frames V0 = %v, Frame() = %v, want %v and %v",
+					test.frames, cpu.V[0], scheduler.Frame(), test.wantV0, test.frames)
+			}
+		})
+	}
+}
+
+// The VIP waits for the display interrupt before drawing, so draws land at the start of a frame
+func TestScheduler_DisplayWait(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	scheduler := NewScheduler(cpu)
+	scheduler.DisplayWait = true
+
+	var draws []uint64
+	cpu.AddObserver(Observer{
+		BeforeExecute: func(cpu *Cpu, inst Instruction) {
+			if inst.Op == OpDRW {
+				draws = append(draws, scheduler.Frame())
+			}
+		},
+	})
+
+	scheduler.RunFrames(3)
+
+	if want := []uint64{1, 2}; !reflect.DeepEqual(draws, want) {
+		t.Errorf("DRW ran in frames %v, want %v", draws, want)
+	}
+}
+
+// Opcodes an extension takes from DRW do not wait for the display
+func TestScheduler_DisplayWaitExtension(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	cpu.AddExtension(Extension{Mask: 0xF000, Value: 0xD000, Mnemonic: "NOP", Handler: nopExtensionHandler})
+	scheduler := NewScheduler(cpu)
+	scheduler.InstructionsPerFrame = 10
+	scheduler.DisplayWait = true
+
+	scheduler.RunFrame()
+	if cpu.V[0] != 4 {
+		t.Errorf("V0 = %v after one frame, want 4 with no display wait", cpu.V[0])
+	}
+}
+
+func TestScheduler_Timers(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	cpu.DT, cpu.ST = 5, 1
+
+	NewScheduler(cpu).RunFrames(3)
+
+	if cpu.DT != 2 || cpu.ST != 0 {
+		t.Errorf("after 3 frames DT = %v, ST = %v, want 2 and 0", cpu.DT, cpu.ST)
+	}
+}
+
+func TestScheduler_Cycles(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	cpu.Timing = DefaultVIPTiming
+	scheduler := NewScheduler(cpu)
+
+	if scheduler.CyclesPerFrame != DefaultVIPTiming.CyclesPerFrame {
+		t.Fatalf("Scheduler.CyclesPerFrame = %v, want %v from the Cpu's timing model", scheduler.CyclesPerFrame, DefaultVIPTiming.CyclesPerFrame)
+	}
+
+	scheduler.RunFrames(2)
+	if frames := cpu.Cycles / DefaultVIPTiming.CyclesPerFrame; frames != 2 {
+		t.Errorf("Cpu.Cycles = %v after 2 frames, %v frames' worth", cpu.Cycles, frames)
+	}
+
+	scheduler.DisplayWait = true
+	scheduler.RunFrame()
+	if cpu.Cycles != 3*DefaultVIPTiming.CyclesPerFrame {
+		t.Errorf("Cpu.Cycles = %v after waiting for the display, want the frame boundary %v", cpu.Cycles, 3*DefaultVIPTiming.CyclesPerFrame)
+	}
+}
+
+type zeroTiming struct{}
+
+func (zeroTiming) Cost(cpu *Cpu, inst Instruction) uint64 {
+	return 0
+}
+
+func TestScheduler_CyclesWithoutCost(t *testing.T) {
+	vip := DefaultVIPTiming
+
+	tests := map[string]struct {
+		timing       TimingModel
+		wantCycles   uint64
+		wantExecuted int
+		wantErr      bool
+	}{
+		"no timing model":   {timing: nil, wantExecuted: DefaultInstructionsPerFrame},
+		"vip timing by ptr": {timing: &vip, wantCycles: vip.CyclesPerFrame},
+		"zero cost":         {timing: zeroTiming{}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newSchedulerTestCpu()
+			cpu.Timing = test.timing
+			scheduler := NewScheduler(cpu)
+			scheduler.CyclesPerFrame = DefaultVIPTiming.CyclesPerFrame
+
+			err := scheduler.RunFrame()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Scheduler.RunFrame() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if test.wantCycles > 0 && cpu.Cycles < test.wantCycles {
+				t.Errorf("Cpu.Cycles = %v after a frame, want at least %v", cpu.Cycles, test.wantCycles)
+			}
+			// Every third instruction is ADD V0, 0x01
+			if want := uint8((test.wantExecuted + 2) / 3); test.wantExecuted > 0 && cpu.V[0] != want {
+				t.Errorf("V0 = %v after a frame, want %v from %v instructions", cpu.V[0], want, test.wantExecuted)
+			}
+		})
+	}
+}
+
+type recordingSubscriber struct {
+	events []string
+	err    error
+}
+
+func (sub *recordingSubscriber) BeginFrame(cpu *Cpu) error {
+	sub.events = append(sub.events, "begin")
+	return nil
+}
+
+func (sub *recordingSubscriber) EndFrame(cpu *Cpu) error {
+	sub.events = append(sub.events, "end")
+	return sub.err
+}
+
+func TestScheduler_Subscribe(t *testing.T) {
+	scheduler := NewScheduler(newSchedulerTestCpu())
+
+	first, second := new(recordingSubscriber), new(recordingSubscriber)
+	scheduler.Subscribe(first)
+	id := scheduler.Subscribe(second)
+
+	scheduler.RunFrame()
+	scheduler.Unsubscribe(id)
+	scheduler.RunFrame()
+
+	if want := []string{"begin", "end", "begin", "end"}; !reflect.DeepEqual(first.events, want) {
+		t.Errorf("subscriber saw %v, want %v", first.events, want)
+	}
+	if want := []string{"begin", "end"}; !reflect.DeepEqual(second.events, want) {
+		t.Errorf("unsubscribed subscriber saw %v, want %v", second.events, want)
+	}
+
+	first.err = errors.New("stop")
+	if err := scheduler.RunFrames(5); !errors.Is(err, first.err) || scheduler.Frame() != 3 {
+		t.Errorf("Scheduler.RunFrames() error = %v at frame %v, want subscriber error at frame 3", err, scheduler.Frame())
+	}
+}
+
+func TestScheduler_FrameFunc(t *testing.T) {
+	scheduler := NewScheduler(newSchedulerTestCpu())
+
+	var frames []uint64
+	scheduler.Subscribe(FrameFunc(func(cpu *Cpu) error {
+		frames = append(frames, scheduler.Frame())
+		return nil
+	}))
+	scheduler.RunFrames(3)
+
+	if want := []uint64{1, 2, 3}; !reflect.DeepEqual(frames, want) {
+		t.Errorf("FrameFunc saw frames %v, want %v", frames, want)
+	}
+}
+
+func TestScheduler_MovieAndRewind(t *testing.T) {
+	cpu := newSchedulerTestCpu()
+	scheduler := NewScheduler(cpu)
+	scheduler.InstructionsPerFrame = 7
+	scheduler.DisplayWait = true
+
+	movie := NewMovie(scheduler.Settings())
+	movie.CheckpointInterval = 1
+	rb := NewRewindBuffer(1 << 20)
+	scheduler.Subscribe(movie)
+	scheduler.Subscribe(rb)
+
+	for frame := range 10 {
+		cpu.Keypad.SetState(uint16(frame))
+		scheduler.RunFrame()
+	}
+
+	if len(movie.Keys) != 10 || rb.Len() != 10 {
+		t.Fatalf("recorded %v movie frames and %v rewind frames, want 10 of each", len(movie.Keys), rb.Len())
+	}
+
+	replayScheduler, err := NewSchedulerWithSettings(movie.Settings)
+	if err != nil {
+		t.Fatalf("NewSchedulerWithSettings() error = %v", err)
+	}
+	replay := replayScheduler.cpu
+	replay.LoadROM(schedulerTestROM)
+	player := NewMoviePlayer(movie)
+	replayScheduler.Subscribe(player)
+
+	err = replayScheduler.RunFrames(10)
+	if err != nil {
+		t.Errorf("replay through Scheduler error = %v", err)
+	}
+	if replay.StateHash() != cpu.StateHash() {
+		t.Errorf("replay through Scheduler ended in a different state")
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	scheduler := NewScheduler(newSchedulerTestCpu())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Subscribe(FrameFunc(func(cpu *Cpu) error {
+		if scheduler.Frame() == 5 {
+			cancel()
+		}
+		return nil
+	}))
+
+	err := scheduler.Run(ctx)
+	if !errors.Is(err, context.Canceled) || scheduler.Frame() != 5 {
+		t.Errorf("Scheduler.Run() error = %v after %v frames, want context.Canceled after 5", err, scheduler.Frame())
+	}
+}
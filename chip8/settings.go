@@ -0,0 +1,53 @@
+package chip8
+
+// RunSettings are what a run depends on besides its input - replaying the same input under the same settings
+// repeats the run exactly
+type RunSettings struct {
+	Platform             Platform
+	Seed                 uint64 // Seed for RND. RND has no handler yet, so runs do not depend on it until it is seeded from here.
+	InstructionsPerFrame int
+	CyclesPerFrame       uint64
+	DisplayWait          bool
+	Timing               *VIPTiming // Nil without a VIP timing model
+}
+
+// Settings of the scheduler and its Cpu. A timing model other than VIPTiming cannot be recorded, and is left out.
+func (scheduler *Scheduler) Settings() RunSettings {
+	settings := RunSettings{
+		Platform:             scheduler.cpu.Platform,
+		InstructionsPerFrame: scheduler.InstructionsPerFrame,
+		CyclesPerFrame:       scheduler.CyclesPerFrame,
+		DisplayWait:          scheduler.DisplayWait,
+	}
+
+	switch timing := scheduler.cpu.Timing.(type) {
+	case VIPTiming:
+		settings.Timing = &timing
+	case *VIPTiming:
+		if timing != nil {
+			copied := *timing
+			settings.Timing = &copied
+		}
+	}
+
+	return settings
+}
+
+// Scheduler and Cpu set up as settings describe, ready to load a ROM into and replay
+func NewSchedulerWithSettings(settings RunSettings) (*Scheduler, error) {
+	cpu, err := NewCpuWithPlatform(settings.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Timing != nil {
+		cpu.Timing = *settings.Timing
+	}
+
+	scheduler := NewScheduler(cpu)
+	scheduler.InstructionsPerFrame = settings.InstructionsPerFrame
+	scheduler.CyclesPerFrame = settings.CyclesPerFrame
+	scheduler.DisplayWait = settings.DisplayWait
+
+	return scheduler, nil
+}
@@ -0,0 +1,57 @@
+package chip8
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScheduler_Settings(t *testing.T) {
+	timing := DefaultVIPTiming
+
+	tests := map[string]struct {
+		platform   Platform
+		timing     TimingModel
+		wantTiming *VIPTiming
+	}{
+		"no timing":         {platform: PlatformChip8},
+		"vip timing":        {platform: PlatformVIP, timing: DefaultVIPTiming, wantTiming: &timing},
+		"vip timing by ptr": {platform: PlatformVIP, timing: &timing, wantTiming: &timing},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu, _ := NewCpuWithPlatform(test.platform)
+			cpu.Timing = test.timing
+			scheduler := NewScheduler(cpu)
+			scheduler.InstructionsPerFrame = 12
+			scheduler.DisplayWait = true
+
+			settings := scheduler.Settings()
+			want := RunSettings{
+				Platform:             test.platform,
+				InstructionsPerFrame: 12,
+				CyclesPerFrame:       scheduler.CyclesPerFrame,
+				DisplayWait:          true,
+				Timing:               test.wantTiming,
+			}
+			if !reflect.DeepEqual(settings, want) {
+				t.Errorf("Scheduler.Settings() = %+v, want %+v", settings, want)
+			}
+
+			restored, err := NewSchedulerWithSettings(settings)
+			if err != nil {
+				t.Fatalf("NewSchedulerWithSettings() error = %v", err)
+			}
+			if got := restored.Settings(); !reflect.DeepEqual(got, settings) {
+				t.Errorf("NewSchedulerWithSettings().Settings() = %+v, want %+v", got, settings)
+			}
+		})
+	}
+}
+
+func TestNewSchedulerWithSettings_Invalid(t *testing.T) {
+	_, err := NewSchedulerWithSettings(RunSettings{Platform: Platform{Name: "broken"}})
+	if err == nil {
+		t.Errorf("NewSchedulerWithSettings() did not throw error as wanted")
+	}
+}
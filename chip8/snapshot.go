@@ -0,0 +1,163 @@
+package chip8
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Rect is an area of the display in pixels. A Rect with no width or height is empty.
+type Rect struct {
+	X, Y uint
+	W, H uint
+}
+
+func (rect Rect) Empty() bool {
+	return rect.W == 0 || rect.H == 0
+}
+
+// Smallest Rect covering both
+func (rect Rect) Union(other Rect) Rect {
+	if rect.Empty() {
+		return other
+	}
+	if other.Empty() {
+		return rect
+	}
+
+	x0, y0 := min(rect.X, other.X), min(rect.Y, other.Y)
+	x1, y1 := max(rect.X+rect.W, other.X+other.W), max(rect.Y+rect.H, other.Y+other.H)
+	return Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+}
+
+// Frame is an immutable copy of the display, as handed to renderers
+type Frame struct {
+	Seq    uint64 // Frames published up to and including this one - 0 before the first
+	Damage Rect   // Pixels changed, or recoloured, since the previous Snapshot - empty if nothing changed
+
+	width      uint
+	height     uint
+	pixels     [maxHeight][maxWidth]bool
+	colours    ColourZones // Copy of the display's colour attributes, when hasColours is set
+	hasColours bool
+}
+
+func (frame *Frame) Width() uint {
+	return frame.width
+}
+
+func (frame *Frame) Height() uint {
+	return frame.height
+}
+
+func (frame *Frame) Get(x uint, y uint) (bool, error) {
+	if x >= frame.width || y >= frame.height {
+		return false, fmt.Errorf("pixel coordinate out of range: x: %v, y: %v", x, y)
+	}
+
+	return frame.pixels[y][x], nil
+}
+
+// Colour attributes as of this frame, or nil for a monochrome display
+func (frame *Frame) Colours() *ColourZones {
+	if !frame.hasColours {
+		return nil
+	}
+	return &frame.colours
+}
+
+// Colour pixel (x, y) is shown in - white on black for a monochrome display
+func (frame *Frame) ColourAt(x uint, y uint) (Colour, error) {
+	lit, err := frame.Get(x, y)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case !frame.hasColours && lit:
+		return ColourWhite, nil
+	case !frame.hasColours:
+		return ColourBlack, nil
+	case lit:
+		return frame.colours.zones[y][x/8], nil
+	}
+	return frame.colours.Background(), nil
+}
+
+func (frame *Frame) Dirty() bool {
+	return !frame.Damage.Empty()
+}
+
+// FrameBuffer double-buffers the display for a renderer on another goroutine. The emulation side calls
+// Publish between frames, or subscribes the FrameBuffer to its Scheduler; the renderer calls Snapshot.
+type FrameBuffer struct {
+	mu     sync.Mutex // Guards front and damage - back belongs to the publisher
+	front  *Frame
+	back   *Frame
+	damage Rect // Accumulated since the last Snapshot
+}
+
+func NewFrameBuffer() *FrameBuffer {
+	return &FrameBuffer{front: &Frame{width: width, height: height}, back: &Frame{width: width, height: height}}
+}
+
+// Copies the display and its colours into the back buffer, then swaps it to the front. Only one goroutine may publish.
+func (fb *FrameBuffer) Publish(display *Display) {
+	back, front := fb.back, fb.front // front is only swapped by Publish, so needs no lock to read here
+
+	back.width, back.height = display.width, display.height
+	back.hasColours = display.colours != nil
+	if back.hasColours {
+		back.colours = *display.colours
+	}
+
+	var damage Rect
+	if back.width != front.width || back.height != front.height || back.hasColours != front.hasColours ||
+		back.hasColours && back.colours.background != front.colours.background {
+		damage = Rect{W: display.width, H: display.height}
+	} else if back.hasColours {
+		for y := range min(zoneRows, display.height) {
+			for col := range min(zoneColumns, display.width/8) {
+				if back.colours.zones[y][col] != front.colours.zones[y][col] {
+					damage = damage.Union(Rect{X: col * 8, Y: y, W: 8, H: 1})
+				}
+			}
+		}
+	}
+
+	for y := range display.height {
+		for x := range display.width {
+			val := display.pixel(x, y)
+			back.pixels[y][x] = val
+			if val != front.pixels[y][x] {
+				damage = damage.Union(Rect{X: x, Y: y, W: 1, H: 1})
+			}
+		}
+	}
+
+	fb.mu.Lock()
+	back.Seq = front.Seq + 1
+	fb.front, fb.back = back, front
+	fb.damage = fb.damage.Union(damage)
+	fb.mu.Unlock()
+}
+
+// Latest published frame, with Damage covering everything changed since the previous call
+func (fb *FrameBuffer) Snapshot() Frame {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	frame := *fb.front
+	frame.Damage = fb.damage
+	fb.damage = Rect{}
+	return frame
+}
+
+// A FrameBuffer subscribed to a Scheduler publishes the display at the end of every frame
+func (fb *FrameBuffer) BeginFrame(cpu *Cpu) error {
+	return nil
+}
+
+func (fb *FrameBuffer) EndFrame(cpu *Cpu) error {
+	fb.Publish(cpu.Display)
+	return nil
+}
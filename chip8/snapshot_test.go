@@ -0,0 +1,166 @@
+package chip8
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRect_Union(t *testing.T) {
+	tests := map[string]struct {
+		a, b Rect
+		want Rect
+	}{
+		"both empty":  {a: Rect{}, b: Rect{}, want: Rect{}},
+		"empty left":  {a: Rect{}, b: Rect{X: 3, Y: 4, W: 1, H: 1}, want: Rect{X: 3, Y: 4, W: 1, H: 1}},
+		"empty right": {a: Rect{X: 3, Y: 4, W: 2, H: 2}, b: Rect{X: 9, Y: 9}, want: Rect{X: 3, Y: 4, W: 2, H: 2}},
+		"disjoint":    {a: Rect{X: 1, Y: 1, W: 1, H: 1}, b: Rect{X: 10, Y: 5, W: 2, H: 3}, want: Rect{X: 1, Y: 1, W: 11, H: 7}},
+		"contained":   {a: Rect{X: 0, Y: 0, W: 64, H: 32}, b: Rect{X: 10, Y: 5, W: 2, H: 3}, want: Rect{X: 0, Y: 0, W: 64, H: 32}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.a.Union(test.b)
+			if got != test.want {
+				t.Errorf("Rect.Union() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFrameBuffer_Snapshot(t *testing.T) {
+	display := NewDisplay()
+	fb := NewFrameBuffer()
+
+	if frame := fb.Snapshot(); frame.Seq != 0 || frame.Dirty() {
+		t.Errorf("Snapshot() before Publish has Seq %v, Dirty() %v, want 0 and false", frame.Seq, frame.Dirty())
+	}
+
+	display.Set(1, 1, true)
+	fb.Publish(display)
+	first := fb.Snapshot()
+	if want := (Rect{X: 1, Y: 1, W: 1, H: 1}); first.Seq != 1 || first.Damage != want {
+		t.Errorf("Snapshot() has Seq %v, Damage %+v, want 1 and %+v", first.Seq, first.Damage, want)
+	}
+
+	fb.Publish(display)
+	if frame := fb.Snapshot(); frame.Seq != 2 || frame.Dirty() {
+		t.Errorf("Snapshot() of an unchanged display has Seq %v, Dirty() %v, want 2 and false", frame.Seq, frame.Dirty())
+	}
+
+	// Damage accumulates over frames the renderer did not take
+	display.Set(10, 5, true)
+	fb.Publish(display)
+	display.Set(1, 1, false)
+	fb.Publish(display)
+	frame := fb.Snapshot()
+	if want := (Rect{X: 1, Y: 1, W: 10, H: 5}); frame.Seq != 4 || frame.Damage != want {
+		t.Errorf("Snapshot() has Seq %v, Damage %+v, want 4 and %+v", frame.Seq, frame.Damage, want)
+	}
+
+	if got, _ := frame.Get(1, 1); got {
+		t.Errorf("Frame.Get(1, 1) = true after the pixel was cleared")
+	}
+	if got, _ := frame.Get(10, 5); !got {
+		t.Errorf("Frame.Get(10, 5) = false after the pixel was set")
+	}
+	if got, _ := first.Get(1, 1); !got {
+		t.Errorf("earlier Frame changed by later Publish calls")
+	}
+	if _, err := frame.Get(width, 0); err == nil {
+		t.Errorf("Frame.Get(%v, 0) error = %v, wantErr true", width, err)
+	}
+}
+
+func TestFrameBuffer_Scheduler(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformVIP)
+	scheduler := NewScheduler(cpu)
+	fb := NewFrameBuffer()
+	scheduler.Subscribe(fb)
+
+	cpu.Memory.Set8(PlatformVIP.DisplayBase, 0x80)
+	scheduler.RunFrames(3)
+
+	frame := fb.Snapshot()
+	if want := (Rect{W: 1, H: 1}); frame.Seq != 3 || frame.Damage != want {
+		t.Errorf("Snapshot() has Seq %v, Damage %+v, want 3 and %+v", frame.Seq, frame.Damage, want)
+	}
+	if got, _ := frame.Get(0, 0); !got {
+		t.Errorf("Frame.Get(0, 0) = false, want the pixel written to display memory")
+	}
+}
+
+func TestFrameBuffer_Colours(t *testing.T) {
+	cpu, _ := NewCpuWithPlatform(PlatformChip8X)
+	display := cpu.Display
+	fb := NewFrameBuffer()
+
+	display.Set(3, 2, true)
+	fb.Publish(display)
+	first := fb.Snapshot()
+	if want := (Rect{W: width, H: height}); first.Damage != want {
+		t.Errorf("first Snapshot() of a colour display has Damage %+v, want %+v", first.Damage, want)
+	}
+
+	display.fillColour(0, 1, 2, 1, ColourGreen)
+	fb.Publish(display)
+	frame := fb.Snapshot()
+	if want := (Rect{Y: 2, W: 8, H: 1}); frame.Damage != want {
+		t.Errorf("Snapshot() after filling a zone has Damage %+v, want %+v", frame.Damage, want)
+	}
+	if got, _ := frame.ColourAt(3, 2); got != ColourGreen {
+		t.Errorf("Frame.ColourAt(3, 2) = %v, want %v", got, ColourGreen)
+	}
+	if got, _ := first.ColourAt(3, 2); got != ColourRed {
+		t.Errorf("earlier Frame.ColourAt(3, 2) = %v, want %v unchanged by later Publish calls", got, ColourRed)
+	}
+
+	display.cycleBackground()
+	fb.Publish(display)
+	frame = fb.Snapshot()
+	if want := (Rect{W: width, H: height}); frame.Damage != want {
+		t.Errorf("Snapshot() after cycling the background has Damage %+v, want %+v", frame.Damage, want)
+	}
+	if got, _ := frame.ColourAt(0, 0); got != ColourBlack {
+		t.Errorf("Frame.ColourAt(0, 0) = %v, want the background %v", got, ColourBlack)
+	}
+	if frame.Colours() == nil || frame.Colours().Background() != ColourBlack {
+		t.Errorf("Frame.Colours() = %v, want the display's colours", frame.Colours())
+	}
+
+	mono := NewFrameBuffer()
+	mono.Publish(NewDisplay())
+	if frame := mono.Snapshot(); frame.Colours() != nil {
+		t.Errorf("Frame.Colours() = %v for a monochrome display, want nil", frame.Colours())
+	}
+	if _, err := frame.ColourAt(width, 0); err == nil {
+		t.Errorf("Frame.ColourAt(%v, 0) error = %v, wantErr true", width, err)
+	}
+}
+
+// Run with -race
+func TestFrameBuffer_Concurrent(t *testing.T) {
+	display := NewDisplay()
+	fb := NewFrameBuffer()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range uint(100) {
+			display.Set(i%width, i%height, true)
+			fb.Publish(display)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			frame := fb.Snapshot()
+			frame.Get(0, 0)
+		}
+	}()
+	wg.Wait()
+
+	if frame := fb.Snapshot(); frame.Seq != 100 {
+		t.Errorf("Snapshot() has Seq %v after 100 frames", frame.Seq)
+	}
+}
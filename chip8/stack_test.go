@@ -0,0 +1,95 @@
+package chip8
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemoryStack_CallRet(t *testing.T) {
+	// 0x200: CALL 0x206 / LD V0, 0x01 / JP 0x204 / RET
+	cpu := newPlatformCpu(t, PlatformVIP, []byte{0x22, 0x06, 0x60, 0x01, 0x12, 0x04, 0x00, 0xEE})
+
+	cpu.Tick()
+	if cpu.SP != 1 || cpu.Memory.Memory[0xECE] != 0x02 || cpu.Memory.Memory[0xECF] != 0x02 {
+		t.Errorf("after CALL SP = %v, memory at ECE = %02X%02X, want 1 and 0202",
+			cpu.SP, cpu.Memory.Memory[0xECE], cpu.Memory.Memory[0xECF])
+	}
+	if cpu.Stack != nil {
+		t.Errorf("Cpu.Stack = %v, want nil in memory-backed stack mode", cpu.Stack)
+	}
+
+	cpu.Tick()
+	if cpu.SP != 0 || cpu.PC != 0x202 {
+		t.Errorf("after RET SP = %v, PC = %04X, want 0 and 0202", cpu.SP, cpu.PC)
+	}
+}
+
+func TestMemoryStack_Clobbered(t *testing.T) {
+	// 0x200: CALL 0x204 / - / RET
+	cpu := newPlatformCpu(t, PlatformVIP, []byte{0x22, 0x04, 0x00, 0x00, 0x00, 0xEE})
+
+	cpu.Tick()
+	cpu.Memory.Set16(0xECE, 0x0300)
+	cpu.Tick()
+
+	if cpu.PC != 0x300 {
+		t.Errorf("RET to clobbered return address: PC = %04X, want 0300", cpu.PC)
+	}
+}
+
+func TestMemoryStack_Errors(t *testing.T) {
+	tests := map[string]struct {
+		rom   []byte
+		ticks int
+	}{
+		"overflow":  {rom: []byte{0x22, 0x00}, ticks: PlatformVIP.StackDepth + 1}, // CALL 0x200, forever
+		"underflow": {rom: []byte{0x00, 0xEE}, ticks: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := newPlatformCpu(t, PlatformVIP, tt.rom)
+
+			var err error
+			for i := 0; i < tt.ticks && err == nil; i++ {
+				err = cpu.Tick()
+			}
+
+			if err == nil {
+				t.Errorf("Cpu.Tick() error = nil after %v ticks, want stack %v", tt.ticks, name)
+			}
+		})
+	}
+}
+
+// Nested calls push down from ECF, as the VIP interpreter does
+func TestMemoryStack_Layout(t *testing.T) {
+	// 0x200: CALL 0x204 / - / CALL 0x208 / - / CALL 0x20C
+	cpu := newPlatformCpu(t, PlatformVIP, []byte{0x22, 0x04, 0x00, 0x00, 0x22, 0x08, 0x00, 0x00, 0x22, 0x0C})
+
+	for range 3 {
+		cpu.Tick()
+	}
+
+	want := []uint8{0x02, 0x0A, 0x02, 0x06, 0x02, 0x02} // ECA - ECF
+	if got := cpu.Memory.Memory[0xECA:0xED0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("memory at ECA-ECF = % X, want % X", got, want)
+	}
+	if got := cpu.Memory.Memory[0xEC9]; got != 0x00 {
+		t.Errorf("memory at EC9 = %02X, stack grew past its third slot", got)
+	}
+}
+
+func TestMemoryStack_StackEntries(t *testing.T) {
+	cpu := newPlatformCpu(t, PlatformVIP, []byte{0x22, 0x00})
+
+	for range 3 {
+		cpu.Tick()
+	}
+
+	want := make([]uint16, PlatformVIP.StackDepth)
+	want[0], want[1], want[2] = 0x202, 0x202, 0x202
+	if got := cpu.StackEntries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cpu.StackEntries() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package chip8
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// Serialised layout: V, I, PC, SP, DT, ST, Stack, Memory, then the framebuffer packed 8 pixels per byte, then
+// on colour platforms the background and one byte per colour zone, then the keys held on each keypad.
+// Stack and Memory are sized by the platform, so states only load into a Cpu with the same layout. A memory-backed
+// stack is saved as part of Memory.
+func (cpu *Cpu) stateSize() int {
+	size := 0x10 + 2 + 2 + 1 + 1 + 1 + len(cpu.Stack)*2 + len(cpu.Memory.Memory) + cpu.displayStateSize()
+	if cpu.Display.colours != nil {
+		size += 1 + zoneRows*zoneColumns
+	}
+	return size + PadCount*2
+}
+
+// Framebuffer bytes, 8 pixels per byte
+func (cpu *Cpu) displayStateSize() int {
+	return int(cpu.Display.width * cpu.Display.height / 8)
+}
+
+func (cpu *Cpu) SaveState() []byte {
+	state := make([]byte, 0, cpu.stateSize())
+
+	state = append(state, cpu.V[:]...)
+	state = binary.BigEndian.AppendUint16(state, cpu.I)
+	state = binary.BigEndian.AppendUint16(state, cpu.PC)
+	state = append(state, cpu.SP, cpu.DT, cpu.ST)
+
+	for _, val := range cpu.Stack {
+		state = binary.BigEndian.AppendUint16(state, val)
+	}
+
+	state = append(state, cpu.Memory.Memory...)
+
+	for y := range cpu.Display.height {
+		for x := uint(0); x < cpu.Display.width; x += 8 {
+			var b uint8
+			for bit := range uint(8) {
+				if cpu.Display.pixel(x+bit, y) {
+					b |= 0x80 >> bit
+				}
+			}
+			state = append(state, b)
+		}
+	}
+
+	if zones := cpu.Display.colours; zones != nil {
+		state = append(state, uint8(zones.background))
+		for _, row := range zones.zones {
+			for _, colour := range row {
+				state = append(state, uint8(colour))
+			}
+		}
+	}
+
+	for _, keys := range cpu.Keypad.Pads() {
+		state = binary.BigEndian.AppendUint16(state, keys)
+	}
+
+	return state
+}
+
+func (cpu *Cpu) LoadState(state []byte) error {
+	if len(state) != cpu.stateSize() {
+		return fmt.Errorf("invalid state size: %v, want %v", len(state), cpu.stateSize())
+	}
+	if sp := int(state[0x10+4]); sp > cpu.stackDepth() {
+		return fmt.Errorf("invalid stack pointer in state: %v, want up to %v", sp, cpu.stackDepth())
+	}
+
+	copy(cpu.V[:], state)
+	state = state[0x10:]
+
+	cpu.I = binary.BigEndian.Uint16(state)
+	cpu.PC = binary.BigEndian.Uint16(state[2:])
+	cpu.SP, cpu.DT, cpu.ST = state[4], state[5], state[6]
+	state = state[7:]
+
+	for i := range cpu.Stack {
+		cpu.Stack[i] = binary.BigEndian.Uint16(state[i*2:])
+	}
+	state = state[len(cpu.Stack)*2:]
+
+	copy(cpu.Memory.Memory, state)
+	cpu.Memory.reloaded()
+	state = state[len(cpu.Memory.Memory):]
+
+	for y := range cpu.Display.height {
+		for x := uint(0); x < cpu.Display.width; x += 8 {
+			b := state[(y*cpu.Display.width+x)/8]
+			for bit := range uint(8) {
+				cpu.Display.setPixel(x+bit, y, b&(0x80>>bit) != 0)
+			}
+		}
+	}
+	state = state[cpu.displayStateSize():]
+
+	if zones := cpu.Display.colours; zones != nil {
+		zones.background = int(state[0]) % len(backgroundCycle)
+		for y := range zones.zones {
+			for x := range zones.zones[y] {
+				zones.zones[y][x] = Colour(state[1+y*zoneColumns+x]) & 0x7
+			}
+		}
+		state = state[1+zoneRows*zoneColumns:]
+	}
+	cpu.Display.markAllDirty()
+
+	var pads [PadCount]uint16
+	for pad := range pads {
+		pads[pad] = binary.BigEndian.Uint16(state[pad*2:])
+	}
+	cpu.Keypad.SetPads(pads)
+
+	return nil
+}
+
+// FNV-1a hash of the serialised state, used to detect emulation divergence
+func (cpu *Cpu) StateHash() uint64 {
+	hash := fnv.New64a()
+	hash.Write(cpu.SaveState())
+	return hash.Sum64()
+}
@@ -0,0 +1,63 @@
+package chip8
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadState(t *testing.T) {
+	const n_tests = 20
+
+	for i := 0; i < n_tests; i++ {
+		want := getRandomCpuState()
+		want.Keypad.SetPads([PadCount]uint16{uint16(rand.Intn(0x10000)), uint16(rand.Intn(0x10000))})
+
+		got := NewCpu()
+		err := got.LoadState(want.SaveState())
+		if err != nil {
+			t.Fatalf("Cpu.LoadState() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Cpu.LoadState() did not restore saved state: %v", got.GetPrettyCpuState())
+		}
+	}
+}
+
+func TestLoadStateInvalidSP(t *testing.T) {
+	tests := map[string]struct {
+		platform Platform
+		sp       uint8
+		wantErr  bool
+	}{
+		"full stack":        {platform: PlatformChip8, sp: 16},
+		"past stack":        {platform: PlatformChip8, sp: 0x40, wantErr: true},
+		"full memory stack": {platform: PlatformVIP, sp: 12},
+		"past memory stack": {platform: PlatformVIP, sp: 13, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu, _ := NewCpuWithPlatform(test.platform)
+			cpu.SP = test.sp
+			state := cpu.SaveState()
+
+			loaded, _ := NewCpuWithPlatform(test.platform)
+			err := loaded.LoadState(state)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Cpu.LoadState() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr && loaded.SP != 0 {
+				t.Errorf("Cpu.LoadState() set SP = %v despite the error", loaded.SP)
+			}
+		})
+	}
+}
+
+func TestLoadStateInvalidSize(t *testing.T) {
+	err := NewCpu().LoadState(make([]byte, 10))
+	if err == nil {
+		t.Errorf("Cpu.LoadState() did not throw error as wanted")
+	}
+}
@@ -0,0 +1,123 @@
+package chip8
+
+// TimingModel assigns each instruction its cost in machine cycles. Cost is called before inst executes,
+// so conditional costs can be worked out from the Cpu's registers.
+type TimingModel interface {
+	Cost(cpu *Cpu, inst Instruction) uint64
+}
+
+// VIPTiming models the COSMAC VIP interpreter. The 1802 runs 8 clocks per machine cycle at 1.7609 MHz,
+// giving about 3668 machine cycles per 60 Hz frame, of which the display interrupt and its DMA take a share.
+type VIPTiming struct {
+	CyclesPerFrame  uint64
+	InterruptCycles uint64 // Stolen from the interpreter by the display interrupt every frame
+}
+
+var DefaultVIPTiming = VIPTiming{
+	CyclesPerFrame:  3668,
+	InterruptCycles: 1024 + 46, // One DMA cycle per displayed byte across 128 scanlines, plus the interrupt routine
+}
+
+const (
+	vipFetchCycles        = 40 // Fetch and dispatch, paid by every instruction
+	vipSkipCycles         = 4  // Extra for a taken skip
+	vipPageCrossCycles    = 2  // Extra for JP V0 when the target is in another page
+	vipAddIPageCycles     = 6  // Extra for ADD I when I moves to another page
+	vipBCDDigitCycles     = 16 // Extra for LD B per unit of each decimal digit
+	vipRegisterCopyCycles = 14 // Extra for LD [I] and LD V, [I] per register copied
+	vipDrawCycles         = 26
+	vipDrawRowCycles      = 34 // Per sprite row that sits in a single display byte
+	vipDrawSplitRowCycles = 68 // Per sprite row that straddles two display bytes
+)
+
+// Execution cycles per op, not counting fetch - ops with variable cost are adjusted in Cost
+var vipCycles = [opCount]uint64{
+	OpCLS:        24 + 3078,
+	OpRET:        10,
+	OpJP:         12,
+	OpCALL:       26,
+	OpSE_v_byte:  10,
+	OpSNE_v_byte: 10,
+	OpSE_v1_v2:   14,
+	OpLD_v_byte:  6,
+	OpADD_v_byte: 10,
+	OpLD_v1_v2:   12,
+	OpOR_v1_v2:   44,
+	OpAND_v1_v2:  44,
+	OpXOR_v1_v2:  44,
+	OpADD_v1_v2:  44,
+	OpSUB_v1_v2:  44,
+	OpSHR_v1_v2:  44,
+	OpSUBN_v1_v2: 44,
+	OpSHL_v1_v2:  44,
+	OpSNE_v1_v2:  14,
+	OpLD_i_addr:  12,
+	OpJP_v0_addr: 22,
+	OpRND_v_byte: 36,
+	OpSKP:        14,
+	OpSKNP:       14,
+	OpLD_v_dt:    10,
+	OpLD_v_k:     19,
+	OpLD_dt_v:    10,
+	OpLD_st_v:    10,
+	OpADD_i_v:    16,
+	OpLD_f_v:     16,
+	OpLD_b_v:     80,
+	OpLD_mem_v:   14,
+	OpLD_v_mem:   14,
+}
+
+func (timing VIPTiming) Cost(cpu *Cpu, inst Instruction) uint64 {
+	cost := vipFetchCycles + vipCycles[inst.Op]
+	x, y := cpu.V[inst.X], cpu.V[inst.Y]
+
+	switch inst.Op {
+	case OpSE_v_byte:
+		if x == inst.NN {
+			cost += vipSkipCycles
+		}
+	case OpSNE_v_byte:
+		if x != inst.NN {
+			cost += vipSkipCycles
+		}
+	case OpSE_v1_v2:
+		if x == y {
+			cost += vipSkipCycles
+		}
+	case OpSNE_v1_v2:
+		if x != y {
+			cost += vipSkipCycles
+		}
+	case OpSKP, OpSKNP:
+		pressed, _ := cpu.Keypad.IsPressed(x & 0xF)
+		if pressed == (inst.Op == OpSKP) {
+			cost += vipSkipCycles
+		}
+	case OpJP_v0_addr:
+		if (inst.NNN+uint16(cpu.V[0]))&0xFF00 != inst.NNN&0xFF00 {
+			cost += vipPageCrossCycles
+		}
+	case OpADD_i_v:
+		if (cpu.I+uint16(x))&0xFF00 != cpu.I&0xFF00 {
+			cost += vipAddIPageCycles
+		}
+	case OpLD_b_v:
+		cost += vipBCDDigitCycles * uint64(x/100+x/10%10+x%10)
+	case OpLD_mem_v, OpLD_v_mem:
+		cost += vipRegisterCopyCycles * uint64(inst.X+1)
+	case OpDRW:
+		rowCycles := uint64(vipDrawRowCycles)
+		if x%8 != 0 {
+			rowCycles = vipDrawSplitRowCycles
+		}
+		cost += vipDrawCycles + rowCycles*uint64(inst.N)
+	}
+
+	// The display interrupt takes its share at every frame boundary the instruction runs across
+	if timing.CyclesPerFrame > 0 {
+		frames := (cpu.Cycles+cost)/timing.CyclesPerFrame - cpu.Cycles/timing.CyclesPerFrame
+		cost += frames * timing.InterruptCycles
+	}
+
+	return cost
+}
@@ -0,0 +1,81 @@
+package chip8
+
+import "testing"
+
+func TestVIPTiming_Cost(t *testing.T) {
+	tests := map[string]struct {
+		opcode uint16
+		setup  func(cpu *Cpu)
+		want   uint64
+	}{
+		"LD V, byte":         {opcode: 0x6012, want: 40 + 6},
+		"SE not taken":       {opcode: 0x3012, want: 40 + 10},
+		"SE taken":           {opcode: 0x3000, want: 40 + 10 + 4},
+		"SNE V, V taken":     {opcode: 0x9010, setup: func(cpu *Cpu) { cpu.V[1] = 1 }, want: 40 + 14 + 4},
+		"SKP pressed":        {opcode: 0xE09E, setup: func(cpu *Cpu) { cpu.Keypad.Press(0) }, want: 40 + 14 + 4},
+		"SKNP pressed":       {opcode: 0xE0A1, setup: func(cpu *Cpu) { cpu.Keypad.Press(0) }, want: 40 + 14},
+		"JP V0 same page":    {opcode: 0xB210, setup: func(cpu *Cpu) { cpu.V[0] = 0x10 }, want: 40 + 22},
+		"JP V0 page cross":   {opcode: 0xB2F8, setup: func(cpu *Cpu) { cpu.V[0] = 0x10 }, want: 40 + 22 + 2},
+		"ADD I page cross":   {opcode: 0xF01E, setup: func(cpu *Cpu) { cpu.I = 0x2FF; cpu.V[0] = 1 }, want: 40 + 16 + 6},
+		"LD B":               {opcode: 0xF033, setup: func(cpu *Cpu) { cpu.V[0] = 123 }, want: 40 + 80 + 16*6},
+		"LD [I], V3":         {opcode: 0xF355, want: 40 + 14 + 14*4},
+		"DRW aligned":        {opcode: 0xD015, setup: func(cpu *Cpu) { cpu.V[0] = 8 }, want: 40 + 26 + 34*5},
+		"DRW unaligned":      {opcode: 0xD015, setup: func(cpu *Cpu) { cpu.V[0] = 9 }, want: 40 + 26 + 68*5},
+		"DRW zero height":    {opcode: 0xD010, want: 40 + 26},
+		"CLS":                {opcode: 0x00E0, want: 40 + 24 + 3078},
+		"SUPER-CHIP op":      {opcode: 0x00FF, want: 40},
+		"frame boundary":     {opcode: 0x6012, setup: func(cpu *Cpu) { cpu.Cycles = 3668 - 10 }, want: 40 + 6 + 1070},
+		"CLS across a frame": {opcode: 0x00E0, setup: func(cpu *Cpu) { cpu.Cycles = 3668 - 1 }, want: 40 + 24 + 3078 + 1070},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cpu := NewCpu()
+			if test.setup != nil {
+				test.setup(cpu)
+			}
+
+			got := DefaultVIPTiming.Cost(cpu, Decode(test.opcode))
+			if got != test.want {
+				t.Errorf("VIPTiming.Cost() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTick_Cycles(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x01, 0x70, 0x01, 0x12, 0x02}) // LD V0, 0x01 / ADD V0, 0x01 / JP 0x202
+
+	cpu.Tick()
+	if cpu.Cycles != 0 {
+		t.Errorf("Cpu.Cycles = %v without a timing model, want 0", cpu.Cycles)
+	}
+
+	cpu.Timing = DefaultVIPTiming
+	for range 3 {
+		cpu.Tick()
+	}
+
+	if want := uint64(40+10) + (40 + 12) + (40 + 10); cpu.Cycles != want {
+		t.Errorf("Cpu.Cycles = %v after ADD, JP, ADD, want %v", cpu.Cycles, want)
+	}
+}
+
+func TestVIPTiming_Pace(t *testing.T) {
+	cpu := NewCpu()
+	cpu.Timing = DefaultVIPTiming
+	cpu.LoadROM([]byte{0x70, 0x01, 0x12, 0x00}) // ADD V0, 0x01 / JP 0x200
+
+	instructions := 0
+	for cpu.Cycles < 60*DefaultVIPTiming.CyclesPerFrame {
+		cpu.Tick()
+		instructions++
+	}
+
+	// Half ADD, half JP, with the display interrupt taking its share of every frame
+	perFrame := float64(DefaultVIPTiming.CyclesPerFrame-DefaultVIPTiming.InterruptCycles) / 51
+	if got := float64(instructions) / 60; got < perFrame*0.95 || got > perFrame*1.05 {
+		t.Errorf("%.1f instructions per frame, want about %.1f", got, perFrame)
+	}
+}
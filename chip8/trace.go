@@ -0,0 +1,57 @@
+package chip8
+
+import "fmt"
+
+type TraceEntry struct {
+	PC     uint16
+	Opcode uint16
+	Ext    *Extension // Set when an extension decoded the opcode
+}
+
+func (entry TraceEntry) String() string {
+	inst := Decode(entry.Opcode)
+	if entry.Ext != nil {
+		inst = entry.Ext.instruction(entry.Opcode)
+	}
+	return fmt.Sprintf("%04X: %04X  %v", entry.PC, entry.Opcode, inst)
+}
+
+// Trace keeps the most recently executed instructions in a fixed-size ring
+type Trace struct {
+	entries []TraceEntry
+	next    int
+	full    bool
+}
+
+func NewTrace(size int) *Trace {
+	return &Trace{entries: make([]TraceEntry, size)}
+}
+
+func (trace *Trace) Record(pc uint16, opcode uint16) {
+	trace.add(TraceEntry{PC: pc, Opcode: opcode})
+}
+
+func (trace *Trace) recordInstruction(pc uint16, inst Instruction) {
+	trace.add(TraceEntry{PC: pc, Opcode: inst.Opcode, Ext: inst.Ext})
+}
+
+func (trace *Trace) add(entry TraceEntry) {
+	if len(trace.entries) == 0 {
+		return
+	}
+
+	trace.entries[trace.next] = entry
+	trace.next = (trace.next + 1) % len(trace.entries)
+	if trace.next == 0 {
+		trace.full = true
+	}
+}
+
+// Recorded instructions, oldest first
+func (trace *Trace) Entries() []TraceEntry {
+	if !trace.full {
+		return append([]TraceEntry(nil), trace.entries[:trace.next]...)
+	}
+
+	return append(append([]TraceEntry(nil), trace.entries[trace.next:]...), trace.entries[:trace.next]...)
+}
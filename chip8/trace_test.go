@@ -0,0 +1,67 @@
+package chip8
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrace_Entries(t *testing.T) {
+	tests := map[string]struct {
+		size    int
+		records int
+		want    []TraceEntry
+	}{
+		"empty": {
+			size:    4,
+			records: 0,
+			want:    []TraceEntry{},
+		},
+		"partial": {
+			size:    4,
+			records: 2,
+			want:    []TraceEntry{{PC: 0x200, Opcode: 0x6000}, {PC: 0x202, Opcode: 0x6001}},
+		},
+		"wrapped": {
+			size:    3,
+			records: 5,
+			want:    []TraceEntry{{PC: 0x204, Opcode: 0x6002}, {PC: 0x206, Opcode: 0x6003}, {PC: 0x208, Opcode: 0x6004}},
+		},
+		"zero size": {
+			size:    0,
+			records: 5,
+			want:    []TraceEntry{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			trace := NewTrace(test.size)
+
+			for i := range test.records {
+				trace.Record(uint16(0x200+i*2), uint16(0x6000+i))
+			}
+
+			got := trace.Entries()
+			if len(got) == 0 && len(test.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Trace.Entries() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTickRecordsTrace(t *testing.T) {
+	cpu := NewCpu()
+	cpu.Trace = NewTrace(8)
+
+	cpu.Memory.Set16(0x200, 0x6142)
+	cpu.Memory.Set16(0x202, 0x7101)
+	cpu.Tick()
+	cpu.Tick()
+
+	want := []TraceEntry{{PC: 0x200, Opcode: 0x6142}, {PC: 0x202, Opcode: 0x7101}}
+	if got := cpu.Trace.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cpu.Trace.Entries() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+// Command chip8-recomp generates a Go package that runs a CHIP-8 ROM natively.
+//
+// Usage:
+//
+//	chip8-recomp [-pkg name] [-o output.go] rom.ch8
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frasmataz/go-chip8/chip8/recomp"
+)
+
+func main() {
+	pkg := flag.String("pkg", "rom", "package name of the generated code")
+	output := flag.String("o", "", "output file, stdout if empty")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: chip8-recomp [-pkg name] [-o output.go] rom.ch8")
+		os.Exit(2)
+	}
+
+	rom, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	source, err := recomp.Generate(rom, *pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(source)
+		return
+	}
+
+	err = os.WriteFile(*output, source, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}